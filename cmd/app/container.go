@@ -0,0 +1,59 @@
+package main
+
+import (
+	"database/sql"
+
+	"github.com/dmitrymomot/go-app/internal/config"
+	"github.com/dmitrymomot/go-app/internal/modules"
+	"github.com/dmitrymomot/go-app/internal/repository"
+	"github.com/dmitrymomot/go-app/pkg/di"
+	"github.com/dmitrymomot/go-app/pkg/jwt"
+	"github.com/sirupsen/logrus"
+)
+
+// initContainer provides the shared dependencies to the DI container and resolves
+// the services of the application modules, see internal/modules.
+// Optional dependencies, e.g. Redis, are provided only if they are configured.
+func initContainer(cfg *config.Config, logger logrus.FieldLogger, d *deps) *di.Container {
+	c := di.New()
+
+	provide := func(err error) {
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to provide dependency")
+		}
+	}
+
+	provide(di.Supply(c, cfg))
+	provide(di.Supply(c, logger))
+	provide(di.Supply(c, d.db))
+	provide(di.Provide(c, func(c *di.Container) (repository.TxQuerier, error) {
+		db, err := di.Resolve[*sql.DB](c)
+		if err != nil {
+			return nil, err
+		}
+		return repository.NewQuerier(db), nil
+	}))
+	provide(di.Supply(c, d.cacheStore))
+	provide(di.Supply(c, d.rateLimitStore))
+	provide(di.Supply(c, d.healthRegistry))
+
+	if d.redisClient != nil {
+		provide(di.Supply(c, d.redisClient))
+	}
+	if d.sessionManager != nil {
+		provide(di.Supply(c, d.sessionManager))
+	}
+	if d.appMetrics != nil {
+		provide(di.Supply(c, d.appMetrics))
+	}
+	if cfg.JWT.SigningKey != "" {
+		provide(di.Supply(c, jwt.NewInteractor([]byte(cfg.JWT.SigningKey), cfg.JWT.Issuer, cfg.JWT.TTL)))
+	}
+
+	// Register and resolve the constructors of the modules
+	if err := modules.Provide(c, modules.List...); err != nil {
+		logger.WithError(err).Fatal("Failed to init application modules")
+	}
+
+	return c
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dmitrymomot/go-app/internal/config"
+	"github.com/dmitrymomot/go-app/migrations"
+	"github.com/dmitrymomot/go-app/pkg/cache"
+	"github.com/dmitrymomot/go-app/pkg/di"
+	"github.com/dmitrymomot/go-app/pkg/health"
+	"github.com/dmitrymomot/go-app/pkg/lifecycle"
+	"github.com/dmitrymomot/go-app/pkg/metrics"
+	"github.com/dmitrymomot/go-app/pkg/ratelimit"
+	"github.com/dmitrymomot/go-app/pkg/session"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// deps are the dependencies shared by all process roles
+type deps struct {
+	db             *sql.DB
+	redisClient    *redis.Client
+	cacheStore     cache.Store
+	rateLimitStore ratelimit.Store
+	sessionStore   session.Store
+	sessionManager *session.Manager
+	appMetrics     *metrics.Metrics
+	healthRegistry *health.Registry
+
+	// container holds the services of the application modules, see internal/modules
+	container *di.Container
+
+	// infra are the names of the lifecycle hooks closing the connections,
+	// components using the dependencies must depend on them to be stopped first.
+	infra []string
+}
+
+// initDeps connects to the database and Redis and builds the shared dependencies.
+// The connections are closed by the lifecycle manager after all components using them stopped.
+func initDeps(cfg *config.Config, logger logrus.FieldLogger, lc *lifecycle.Manager) *deps {
+	var err error
+	d := &deps{}
+
+	// Init DB connection
+	d.db, err = sql.Open("postgres", cfg.DB.URL)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to init db connection")
+	}
+	mustAppendHook(logger, lc, lifecycle.Closer("db", d.db))
+	d.infra = append(d.infra, "db")
+
+	d.db.SetMaxOpenConns(cfg.DB.MaxOpenConns)
+	d.db.SetMaxIdleConns(cfg.DB.MaxIdleConns)
+
+	if err := d.db.Ping(); err != nil {
+		logger.WithError(err).Fatal("Failed to ping db")
+	}
+
+	// Init Redis connection, if configured
+	if cfg.Redis.URL != "" {
+		redisOpts, err := redis.ParseURL(cfg.Redis.URL)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to parse redis connection string")
+		}
+		d.redisClient = redis.NewClient(redisOpts)
+		mustAppendHook(logger, lc, lifecycle.Closer("redis", d.redisClient))
+		d.infra = append(d.infra, "redis")
+
+		if err := d.redisClient.Ping(context.Background()).Err(); err != nil {
+			logger.WithError(err).Fatal("Failed to ping redis")
+		}
+	}
+
+	// Init cache store: Redis if configured, in-memory LRU otherwise
+	var cacheDeps []string
+	d.cacheStore = cache.NewMemoryStore(cfg.Cache.MemorySize)
+	if d.redisClient != nil {
+		d.cacheStore = cache.NewRedisStore(d.redisClient, cache.WithRedisPrefix(cfg.Cache.KeyPrefix))
+		cacheDeps = []string{"redis"}
+	}
+	mustAppendHook(logger, lc, lifecycle.Closer("cache", d.cacheStore, cacheDeps...))
+	d.infra = append(d.infra, "cache")
+
+	// Init rate limit store: Redis if configured, in-memory otherwise
+	d.rateLimitStore = ratelimit.NewMemoryStore()
+	if d.redisClient != nil {
+		d.rateLimitStore = ratelimit.NewRedisStore(d.redisClient, "ratelimit:")
+	}
+
+	// Init session manager, if enabled
+	if cfg.Session.Enabled {
+		switch cfg.Session.Store {
+		case "cookie":
+			d.sessionStore = session.NewCookieStore()
+		case "postgres":
+			// The table is created by the migrations
+			d.sessionStore = session.NewPostgresStore(d.db, "sessions")
+		case "redis":
+			if d.redisClient == nil {
+				logger.Fatal("Redis session store requires REDIS_URL")
+			}
+			d.sessionStore = session.NewRedisStore(d.redisClient, "session:")
+		default:
+			logger.Fatalf("Unknown session store: %s", cfg.Session.Store)
+		}
+
+		d.sessionManager, err = session.NewManager(
+			d.sessionStore, []byte(cfg.Session.Secret),
+			session.WithEncryptionKey([]byte(cfg.Session.EncryptionKey)),
+			session.WithCookieName(cfg.Session.CookieName),
+			session.WithCookieDomain(cfg.Session.CookieDomain),
+			session.WithCookieSecure(cfg.Session.CookieSecure),
+			session.WithIdleTimeout(cfg.Session.IdleTimeout),
+			session.WithLifetime(cfg.Session.Lifetime),
+			session.WithLogger(logger.WithField("component", "session")),
+		)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to init session manager")
+		}
+	}
+
+	// Init metrics: HTTP requests, DB connection pool, Go runtime and build info
+	if cfg.Metrics.Enabled {
+		d.appMetrics = metrics.New(
+			metrics.WithNamespace(cfg.Metrics.Namespace),
+			metrics.WithBuildInfo(cfg.App.Name, cfg.App.BuildTag),
+			metrics.WithDB("main", d.db),
+		)
+	}
+
+	// Init health checks registry
+	d.healthRegistry = health.New(
+		health.WithTimeout(cfg.Health.Timeout),
+		health.WithCacheTTL(cfg.Health.CacheTTL),
+	)
+	mustRegisterCheck(logger, d.healthRegistry, "db", health.DB(d.db))
+	if d.redisClient != nil {
+		mustRegisterCheck(logger, d.healthRegistry, "redis", health.Redis(d.redisClient))
+	}
+	mustRegisterCheck(logger, d.healthRegistry, "migrations",
+		health.Migrations(d.db, migrations.Source(cfg.DB.MigrationsDir), cfg.DB.MigrationsTable),
+		health.WithCheckCacheTTL(time.Minute),
+	)
+
+	return d
+}
+
+// mustAppendHook registers the lifecycle hooks or stops the application
+func mustAppendHook(logger logrus.FieldLogger, lc *lifecycle.Manager, hooks ...lifecycle.Hook) {
+	if err := lc.Append(hooks...); err != nil {
+		logger.WithError(err).Fatal("Failed to register lifecycle hook")
+	}
+}
+
+// mustRegisterCheck registers the health check or stops the application
+func mustRegisterCheck(logger logrus.FieldLogger, r *health.Registry, name string, fn health.CheckFunc, opts ...health.CheckOption) {
+	if err := r.Register(name, fn, opts...); err != nil {
+		logger.WithError(err).Fatalf("Failed to register %s health check", name)
+	}
+}
@@ -5,40 +5,48 @@ import (
 	"net/url"
 	"os"
 
+	"github.com/dmitrymomot/go-app/internal/config"
+	"github.com/dmitrymomot/go-app/pkg/logger"
+	"github.com/dmitrymomot/go-app/pkg/tracing"
 	_ "github.com/lib/pq" // init pg driver
 	"github.com/sirupsen/logrus"
 )
 
 func init() {
+	// Register the types for gob, used to serialize session values
+	gob.Register(url.Values{})
+	gob.Register(map[string]string{})
+	gob.Register(map[string][]string{})
+	gob.Register(map[string]interface{}{})
+	gob.Register(map[string][]interface{}{})
+	gob.Register(map[interface{}]interface{}{})
+}
+
+// initLogger sets up the standard logger
+func initLogger(cfg *config.Config) {
 	// SetLevel sets the global log level used by the standard logger.
-	lvl, err := logrus.ParseLevel(appLogLevel)
+	// It can be changed at runtime with SIGUSR1/SIGUSR2 or the admin endpoint.
+	lvl, err := logrus.ParseLevel(cfg.App.LogLevel)
 	if err != nil {
 		lvl = logrus.InfoLevel
 	}
 	logrus.SetLevel(lvl)
 
 	// SetReportCaller sets whether the standard logger will include the calling
-	// method as a field.
-	logrus.SetReportCaller(true)
-
-	// // Default formatter is the standard logger.
-	// logrus.SetFormatter(&logrus.TextFormatter{
-	// 	ForceColors: true,
-	// })
-	// Log as JSON instead of the default ASCII formatter.
-	logrus.SetFormatter(&logrus.JSONFormatter{
-		PrettyPrint: true,
-	})
+	// method as a field. It's slow, so it's disabled by default.
+	logrus.SetReportCaller(cfg.Log.ReportCaller)
+
+	// Log format: json, logfmt or colored text
+	formatter, err := logger.NewFormatter(cfg.Log.Format)
+	if err != nil {
+		formatter = &logrus.JSONFormatter{}
+	}
+	logrus.SetFormatter(formatter)
 
 	// Output to stdout instead of the default stderr
 	// Can be any io.Writer, see below for File example
 	logrus.SetOutput(os.Stdout)
 
-	// Register the types for gob
-	gob.Register(url.Values{})
-	gob.Register(map[string]string{})
-	gob.Register(map[string][]string{})
-	gob.Register(map[string]interface{}{})
-	gob.Register(map[string][]interface{}{})
-	gob.Register(map[interface{}]interface{}{})
+	// Add trace_id and span_id fields to entries logged with a traced context
+	logrus.AddHook(tracing.LogrusHook{})
 }
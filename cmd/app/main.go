@@ -1,62 +1,176 @@
 package main
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
+	"os"
+	"time"
 
-	"github.com/dmitrymomot/go-pkg/httpserver"
+	"github.com/dmitrymomot/go-app/internal/config"
+	"github.com/dmitrymomot/go-app/internal/migratecli"
+	"github.com/dmitrymomot/go-app/pkg/lifecycle"
+	applog "github.com/dmitrymomot/go-app/pkg/logger"
+	"github.com/dmitrymomot/go-app/pkg/tracing"
 	"github.com/dmitrymomot/go-utils"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/sync/errgroup"
+)
+
+const usage = `Usage: app [command]
+
+Commands:
+  serve          run the HTTP server
+  worker         run the queue workers
+  scheduler      run the scheduler of periodic jobs
+  all            run the HTTP server, queue workers and scheduler, if enabled (default)
+  migrate        run the database migrations, see "app migrate --help"
+  config check   print the effective configuration and its problems
+
+Every role serves health probes on HTTP_PORT, so each of them can be scaled independently.
+`
+
+// Process roles
+const (
+	roleServe     = "serve"
+	roleWorker    = "worker"
+	roleScheduler = "scheduler"
+	roleAll       = "all"
 )
 
 func main() {
+	cfg, err := config.Load()
+
+	role := roleAll
+	if len(os.Args) > 1 {
+		role = os.Args[1]
+	}
+
+	switch role {
+	case roleServe, roleWorker, roleScheduler, roleAll:
+	case "config":
+		if len(os.Args) > 2 && os.Args[2] == "check" {
+			os.Exit(runConfigCheck(cfg, err))
+		}
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	case "migrate":
+		os.Exit(runMigrate(cfg, err, os.Args[2:]))
+	case "help", "-h", "--help":
+		fmt.Fprint(os.Stdout, usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", role, usage)
+		os.Exit(2)
+	}
+
+	initLogger(cfg)
+
 	logger := logrus.WithFields(logrus.Fields{
-		"app":       appName,
-		"build_tag": buildTag,
+		"app":       cfg.App.Name,
+		"build_tag": cfg.App.BuildTag,
+		"role":      role,
 		"component": "main",
 	})
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to load config")
+	}
+	logger.WithFields(cfg.Redacted()).Info("Effective configuration")
+
 	defer func() { logger.Info("Server successfully shutdown") }()
 
-	// Init DB connection
-	db, err := sql.Open("postgres", dbConnString)
+	// Init tracing, spans are propagated even if exporting is disabled
+	shutdownTracing, err := tracing.Init(context.Background(),
+		tracing.WithService(cfg.App.Name, cfg.App.BuildTag),
+		tracing.WithExporter(cfg.Tracing.Exporter),
+		tracing.WithFile(cfg.Tracing.File),
+		tracing.WithSampleRatio(cfg.Tracing.SampleRatio),
+	)
 	if err != nil {
-		logger.WithError(err).Fatal("Failed to init db connection")
+		logger.WithError(err).Fatal("Failed to init tracing")
 	}
-	defer db.Close()
+	defer func() {
+		// Flush pending spans
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.WithError(err).Error("Failed to shutdown tracing")
+		}
+	}()
 
-	db.SetMaxOpenConns(dbMaxOpenConns)
-	db.SetMaxIdleConns(dbMaxIdleConns)
+	// Init lifecycle manager: it starts components in the order of their dependencies
+	// and stops them in reverse: readiness probe, servers and workers, connections
+	lc := lifecycle.New(
+		lifecycle.WithLogger(logger.WithField("component", "lifecycle")),
+		lifecycle.WithStopTimeout(cfg.App.ShutdownTimeout),
+	)
 
-	if err := db.Ping(); err != nil {
-		logger.WithError(err).Fatal("Failed to ping db")
+	// Init DB and Redis connections, stores, metrics and health checks shared by all roles
+	d := initDeps(cfg, logger, lc)
+
+	// Resolve the services of the application modules, errors fail the startup
+	d.container = initContainer(cfg, logger, d)
+
+	// Register the process roles
+	switch role {
+	case roleServe:
+		registerServe(lc, cfg, logger, d)
+	case roleWorker:
+		registerProbeServer(lc, cfg, logger, d)
+		registerWorkers(lc, cfg, logger, d)
+	case roleScheduler:
+		registerProbeServer(lc, cfg, logger, d)
+		registerScheduler(lc, cfg, logger, d)
+	case roleAll:
+		registerServe(lc, cfg, logger, d)
+		registerWorkers(lc, cfg, logger, d)
+		if cfg.Scheduler.Enabled {
+			registerScheduler(lc, cfg, logger, d)
+		}
 	}
 
-	// Create a context with a timeout and set the Server's context
+	// Change log level at runtime: SIGUSR1 is more verbose, SIGUSR2 is less verbose
+	mustAppendHook(logger, lc, lifecycle.Hook{
+		Name: "log-level-signals",
+		Run: func(ctx context.Context) error {
+			applog.WatchLevelSignals(ctx, logrus.StandardLogger())
+			return ctx.Err()
+		},
+	})
+
+	// Create a context which is canceled on SIGINT or SIGTERM
 	ctx, cancel := utils.NewContextWithCancel(logger.WithField("component", "context"))
 	defer cancel()
 
-	// Create a new errgroup
-	eg, _ := errgroup.WithContext(ctx)
-
-	// Init router with default middlewares and routes
-	r := initRouter()
+	// Run the components until the context is canceled or one of them fails
+	if err := lc.Run(ctx); err != nil {
+		logger.WithError(err).Error("Server stopped with error")
+	}
+}
 
-	// TODO: Add your routes here
+// runMigrate runs the migrate command line with the application config.
+// Usage: app migrate [--dry-run] <command> [arguments]
+func runMigrate(cfg *config.Config, cfgErr error, args []string) int {
+	cmd, err := migratecli.Parse(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprint(os.Stderr, migratecli.Usage)
+		return 2
+	}
 
-	// Create a new server
-	server := httpserver.NewServer(
-		fmt.Sprintf(":%d", httpPort), r,
-		httpserver.WithShutdownTimeout(httpShutdownTimeout),
-		httpserver.WithLogger(logger.WithField("component", "http-server")),
-	)
-	defer server.Shutdown()
+	initLogger(cfg)
+	logger := logrus.WithFields(logrus.Fields{
+		"app":       cfg.App.Name,
+		"build_tag": cfg.App.BuildTag,
+		"role":      "migrate",
+	})
 
-	// Run the server
-	eg.Go(func() error { return server.Run(ctx) })
+	if cfgErr != nil && cmd.NeedsConfig() {
+		logger.WithError(cfgErr).Error("Failed to load config")
+		return 1
+	}
 
-	// Wait for the server to finish
-	if err := eg.Wait(); err != nil {
-		logger.WithError(err).Error("Server stopped with error")
+	if err := cmd.Run(logger, cfg); err != nil {
+		logger.WithError(err).Error("Failed to run migrate command")
+		return 1
 	}
+	return 0
 }
@@ -1,48 +1,131 @@
 package main
 
 import (
+	"crypto/subtle"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 
+	"github.com/dmitrymomot/go-app/internal/config"
+	"github.com/dmitrymomot/go-app/pkg/cache"
+	"github.com/dmitrymomot/go-app/pkg/cors"
+	"github.com/dmitrymomot/go-app/pkg/csrf"
+	"github.com/dmitrymomot/go-app/pkg/health"
+	"github.com/dmitrymomot/go-app/pkg/httpcache"
+	"github.com/dmitrymomot/go-app/pkg/logger"
+	"github.com/dmitrymomot/go-app/pkg/metrics"
+	"github.com/dmitrymomot/go-app/pkg/ratelimit"
+	"github.com/dmitrymomot/go-app/pkg/session"
+	"github.com/dmitrymomot/go-app/pkg/tracing"
 	"github.com/dmitrymomot/go-pkg/httpserver"
 	"github.com/dmitrymomot/go-pkg/middlewares"
+	"github.com/dmitrymomot/go-pkg/response"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/cors"
+	"github.com/sirupsen/logrus"
 )
 
+// errUnauthorized is returned for admin requests without a valid token
+var errUnauthorized = errors.New("unauthorized")
+
 // init router with default middlewares and routes
-func initRouter() *chi.Mux {
+func initRouter(cfg *config.Config, healthRegistry *health.Registry, appMetrics *metrics.Metrics, cacheStore cache.Store, sessionManager *session.Manager, rateLimitStore ratelimit.Store) (*chi.Mux, error) {
 	r := chi.NewRouter()
 
+	// CORS policies, unsafe combinations fail at startup
+	corsMiddleware, err := cors.Middleware(
+		cfg.CORS.Policy(),
+		// Per-route-group policies, e.g. a public read-only API:
+		// cors.WithPathPolicy("/api/public/*", cors.Policy{
+		// 	AllowedOrigins: []string{"*"},
+		// 	AllowedMethods: []string{http.MethodGet, http.MethodHead},
+		// }),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init CORS middleware: %w", err)
+	}
+
+	// Requests metrics, must be the first middleware to count recovered panics and rejected requests
+	if appMetrics != nil {
+		r.Use(appMetrics.Middleware)
+	}
+
+	// Request-scoped logger, use logger.FromContext(ctx) in handlers
+	// and logger.SetSubject(ctx, userID) in the authentication middleware
+	loggerOpts := []logger.Option{
+		logger.WithSampleRate(cfg.Log.AccessSampleRate),
+		logger.WithExcludedPaths(cfg.Log.AccessExcludePaths...),
+	}
+	if !cfg.Log.AccessEnabled {
+		loggerOpts = append(loggerOpts, logger.WithoutAccessLog())
+	}
+
 	r.Use(
-		// middleware.Logger,
+		// Extract W3C trace context and trace the request as a span named by the route pattern
+		tracing.Middleware,
 		middleware.Recoverer,
-		middleware.AllowContentType(allowContentTypes...),
+		middleware.RealIP,
+		middleware.RequestID,
+		logger.Middleware(logrus.WithField("component", "http"), loggerOpts...),
+		middleware.AllowContentType(cfg.HTTP.AllowContentTypes...),
 		middleware.CleanPath,
 		middleware.StripSlashes,
 		middleware.GetHead,
-		middleware.NoCache,
-		middleware.RealIP,
-		middleware.RequestID,
-		middleware.Timeout(httpRequestTimeout),
+		// No-cache headers by default, routes opt in to caching with httpcache.Use
+		httpcache.Middleware(
+			httpcache.WithStore(cacheStore),
+			httpcache.WithErrorHandler(func(err error) {
+				logrus.WithError(err).Warn("HTTP cache backend error")
+			}),
+		),
+		middleware.Timeout(cfg.HTTP.RequestTimeout),
 		middleware.SetHeader("X-Content-Type-Options", "nosniff"),
 		middleware.SetHeader("X-Frame-Options", "deny"),
 
-		// Basic CORS
+		// CORS
 		// for more ideas, see: https://developer.github.com/v3/#cross-origin-resource-sharing
-		cors.Handler(cors.Options{
-			AllowedOrigins:   corsAllowedOrigins,
-			AllowedMethods:   corsAllowedMethods,
-			AllowedHeaders:   corsAllowedHeaders,
-			AllowCredentials: corsAllowedCredentials,
-			MaxAge:           corsMaxAge, // Maximum value not ignored by any of major browsers
-		}),
+		corsMiddleware,
 
 		// Uses for testing error response with needed status code
 		middlewares.Testing(),
 	)
 
+	// Rate limiting by client IP, must be used after middleware.RealIP
+	if cfg.RateLimit.Enabled {
+		r.Use(ratelimit.Middleware(
+			rateLimitStore,
+			cfg.RateLimit.Limit(),
+			ratelimit.WithKeyFunc(ratelimit.KeyByIP),
+			// Per-route overrides of the default limit
+			ratelimit.WithRouteLimits(r, map[string]ratelimit.Limit{
+				"/health":        ratelimit.Unlimited,
+				"/livez":         ratelimit.Unlimited,
+				"/readyz":        ratelimit.Unlimited,
+				"/startupz":      ratelimit.Unlimited,
+				cfg.Metrics.Path: ratelimit.Unlimited,
+			}),
+			ratelimit.WithErrorHandler(func(_ *http.Request, err error) {
+				logrus.WithError(err).Warn("Rate limit store error")
+			}),
+		))
+	}
+
+	// Load and save the session per request, if sessions are enabled
+	if sessionManager != nil {
+		r.Use(sessionManager.Middleware)
+	}
+
+	// CSRF protection of cookie-authenticated routes,
+	// the token is kept in the session if sessions are enabled
+	if cfg.CSRF.Enabled {
+		r.Use(csrf.Middleware(
+			csrf.WithTrustedOrigins(cfg.CSRF.TrustedOrigins...),
+			csrf.WithCookieSecure(cfg.CSRF.CookieSecure),
+			csrf.WithExposeHeader(),
+		))
+	}
+
 	// Default error handlers
 	r.NotFound(httpserver.NotFoundHandler())
 	r.MethodNotAllowed(httpserver.MethodNotAllowedHandler())
@@ -50,18 +133,102 @@ func initRouter() *chi.Mux {
 	// Health check endpoint, returns 204 No Content
 	r.HandleFunc("/health", httpserver.HealthCheckHandler())
 
+	// Liveness, readiness and startup probes: /livez, /readyz and /startupz,
+	// add ?verbose to get the JSON report of every check
+	healthRegistry.Routes(r)
+
+	// Admin endpoints, if the admin server is disabled
+	if cfg.HTTP.AdminPort == 0 {
+		mountAdminRoutes(r, cfg, appMetrics)
+	}
+
 	// Static files
-	if isStaticFilesEnabled {
+	if cfg.Static.Enabled {
 		r.Handle(
-			fmt.Sprintf("%s/*", strings.TrimSuffix(staticFilesPrefix, "/")),
-			httpserver.FileServer(staticFilesDir, staticFilesPrefix),
+			fmt.Sprintf("%s/*", strings.TrimSuffix(cfg.Static.Prefix, "/")),
+			httpserver.FileServer(cfg.Static.Dir, cfg.Static.Prefix),
 		)
 	}
 
-	if appDebug {
+	if cfg.App.Debug {
 		// Profiler endpoints, only for debug mode
 		r.Mount("/debug", middleware.Profiler())
 	}
 
+	return r, nil
+}
+
+// init router of the internal admin server
+func initAdminRouter(cfg *config.Config, appMetrics *metrics.Metrics) *chi.Mux {
+	r := chi.NewRouter()
+
+	r.Use(
+		middleware.Recoverer,
+		middleware.NoCache,
+	)
+
+	r.NotFound(httpserver.NotFoundHandler())
+	r.MethodNotAllowed(httpserver.MethodNotAllowedHandler())
+
+	mountAdminRoutes(r, cfg, appMetrics)
+
+	return r
+}
+
+// mount admin endpoints to the router
+func mountAdminRoutes(r chi.Router, cfg *config.Config, appMetrics *metrics.Metrics) {
+	// Prometheus metrics
+	if appMetrics != nil {
+		r.Handle(cfg.Metrics.Path, appMetrics.Handler())
+	}
+
+	// Authenticated endpoints, disabled if the admin token is not set
+	if cfg.HTTP.AdminToken != "" {
+		r.Group(func(r chi.Router) {
+			r.Use(requireBearerToken(cfg.HTTP.AdminToken))
+
+			// Get or change the log level at runtime: PUT {"level": "debug"}
+			r.Handle("/admin/log-level", logger.LevelHandler(logrus.StandardLogger()))
+		})
+	}
+}
+
+// requireBearerToken rejects requests without the given bearer token,
+// the "Bearer" scheme is required and case-insensitive
+func requireBearerToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme, got, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+			if !ok || !strings.EqualFold(scheme, "Bearer") || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				response.JSON(w, response.NewError( // nolint:errcheck
+					http.StatusUnauthorized, errUnauthorized, "Invalid admin token.", nil,
+				))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// initProbeRouter returns the router of the background roles: worker and scheduler.
+// It serves the health probes and, if the admin server is disabled, the admin endpoints.
+func initProbeRouter(cfg *config.Config, healthRegistry *health.Registry, appMetrics *metrics.Metrics) *chi.Mux {
+	r := chi.NewRouter()
+
+	r.Use(
+		middleware.Recoverer,
+		middleware.NoCache,
+	)
+
+	r.NotFound(httpserver.NotFoundHandler())
+	r.MethodNotAllowed(httpserver.MethodNotAllowedHandler())
+
+	r.HandleFunc("/health", httpserver.HealthCheckHandler())
+	healthRegistry.Routes(r)
+
+	if cfg.HTTP.AdminPort == 0 {
+		mountAdminRoutes(r, cfg, appMetrics)
+	}
+
 	return r
 }
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"time"
+
+	"github.com/dmitrymomot/go-app/internal/config"
+	"github.com/dmitrymomot/go-app/pkg/lifecycle"
+	"github.com/dmitrymomot/go-app/pkg/pglock"
+	"github.com/dmitrymomot/go-app/pkg/scheduler"
+	"github.com/dmitrymomot/go-app/pkg/session"
+	"github.com/sirupsen/logrus"
+)
+
+// registerScheduler registers the scheduler of periodic jobs.
+// Jobs are coordinated with advisory locks, so any number of instances can run it.
+// On shutdown the running jobs are waited for at most the app shutdown timeout.
+func registerScheduler(lc *lifecycle.Manager, cfg *config.Config, logger *logrus.Entry, d *deps) {
+	loc, err := time.LoadLocation(cfg.Scheduler.Timezone)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to load scheduler time zone")
+	}
+
+	sched := scheduler.New(
+		scheduler.WithLocker(pglock.NewLocker(d.db)),
+		// The table is created by the migrations
+		scheduler.WithStore(scheduler.NewPostgresStore(d.db, "scheduler_jobs")),
+		scheduler.WithLogger(logger.WithField("component", "scheduler")),
+		scheduler.WithLocation(loc),
+		scheduler.WithJobTimeout(cfg.Scheduler.JobTimeout),
+	)
+
+	// Remove expired sessions from the server-side store
+	if cleaner, ok := d.sessionStore.(session.Cleaner); ok {
+		if err := sched.Add("cleanup-expired-sessions", "@hourly", cleaner.DeleteExpired); err != nil {
+			logger.WithError(err).Fatal("Failed to register scheduler job")
+		}
+	}
+
+	// TODO: Register your periodic jobs here, e.g.:
+	// sched.Add("cleanup-expired-tokens", "@hourly", cleanupExpiredTokens)
+
+	mustAppendHook(logger, lc, lifecycle.Hook{
+		Name:      "scheduler",
+		DependsOn: d.infra,
+		Run:       sched.Run,
+	})
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dmitrymomot/go-app/internal/config"
+	"github.com/dmitrymomot/go-app/internal/modules"
+	"github.com/dmitrymomot/go-app/pkg/lifecycle"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// registerServe registers the HTTP server and the internal admin server, if enabled
+func registerServe(lc *lifecycle.Manager, cfg *config.Config, logger *logrus.Entry, d *deps) {
+	// Init router with default middlewares and routes
+	r, err := initRouter(cfg, d.healthRegistry, d.appMetrics, d.cacheStore, d.sessionManager, d.rateLimitStore)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to init router")
+	}
+
+	// Mount the routes of the application modules, add modules to internal/modules.List.
+	// Use httpcache.Use to opt a route in to HTTP caching, e.g.:
+	// r.With(httpcache.Use(httpcache.Policy{MaxAge: time.Minute})).Get("/articles", handler)
+	if err := modules.Routes(d.container, r, modules.List...); err != nil {
+		logger.WithError(err).Fatal("Failed to mount routes of application modules")
+	}
+
+	registerHTTPServers(lc, cfg, logger, d, r)
+}
+
+// registerProbeServer registers the HTTP server with health probes for the background roles,
+// and the internal admin server, if enabled
+func registerProbeServer(lc *lifecycle.Manager, cfg *config.Config, logger *logrus.Entry, d *deps) {
+	registerHTTPServers(lc, cfg, logger, d, initProbeRouter(cfg, d.healthRegistry, d.appMetrics))
+}
+
+// registerHTTPServers registers the server with the given router on the HTTP port,
+// the admin server on the admin port, if enabled, and the readiness hook.
+// On shutdown the readiness probe fails first, then the servers drain in-flight requests.
+func registerHTTPServers(lc *lifecycle.Manager, cfg *config.Config, logger *logrus.Entry, d *deps, r chi.Router) {
+	servers := []string{"http-server"}
+	mustAppendHook(logger, lc, lifecycle.HTTPServer("http-server", &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.HTTP.Port),
+		Handler: r,
+	}, cfg.HTTP.ShutdownTimeout, d.infra...))
+
+	// Run the internal admin server, if enabled
+	if cfg.HTTP.AdminPort > 0 {
+		servers = append(servers, "admin-server")
+		mustAppendHook(logger, lc, lifecycle.HTTPServer("admin-server", &http.Server{
+			Addr:    fmt.Sprintf(":%d", cfg.HTTP.AdminPort),
+			Handler: initAdminRouter(cfg, d.appMetrics),
+		}, cfg.HTTP.ShutdownTimeout, d.infra...))
+	}
+
+	// Fail readiness probe as soon as shutdown begins, so load balancers drain traffic
+	// before the servers stop accepting connections
+	mustAppendHook(logger, lc, lifecycle.Hook{
+		Name:      "readiness",
+		DependsOn: servers,
+		OnStop: func(ctx context.Context) error {
+			d.healthRegistry.Shutdown()
+			select {
+			case <-time.After(cfg.HTTP.DrainDelay):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+		StopTimeout: cfg.HTTP.DrainDelay + time.Second,
+	})
+}
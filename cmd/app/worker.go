@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+
+	"github.com/dmitrymomot/go-app/internal/config"
+	"github.com/dmitrymomot/go-app/pkg/lifecycle"
+	"github.com/sirupsen/logrus"
+)
+
+// worker is a long-running consumer, e.g. of a job queue.
+// It must return when the context is done.
+type worker struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// initWorkers returns the queue workers of the application
+func initWorkers(cfg *config.Config, logger *logrus.Entry, d *deps) []worker {
+	// TODO: Add your queue workers here, e.g.:
+	// return []worker{{name: "emails", run: di.MustResolve[*emails.Consumer](d.container).Run}}
+	// and register the queue lag health check:
+	// mustRegisterCheck(logger, d.healthRegistry, "queue", health.QueueLag(queueLatency, time.Minute))
+	return nil
+}
+
+// registerWorkers registers the queue workers.
+// On shutdown the workers are given the app shutdown timeout to finish in-flight jobs.
+func registerWorkers(lc *lifecycle.Manager, cfg *config.Config, logger *logrus.Entry, d *deps) {
+	workers := initWorkers(cfg, logger, d)
+	if len(workers) == 0 {
+		logger.Warn("No queue workers registered")
+		return
+	}
+
+	for _, w := range workers {
+		mustAppendHook(logger, lc, lifecycle.Hook{
+			Name:      "worker:" + w.name,
+			DependsOn: d.infra,
+			Run:       w.run,
+		})
+	}
+}
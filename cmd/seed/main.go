@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/dmitrymomot/go-app/internal/config"
+	"github.com/dmitrymomot/go-app/migrations"
+	"github.com/dmitrymomot/go-app/pkg/seeder"
+	_ "github.com/lib/pq" // init pg driver
+	"github.com/sirupsen/logrus"
+)
+
+const usage = `Usage: seed <env>
+
+Applies the seeds of the environment from migrations/seeds/<env> and the Go seeders
+registered for it. Applied seeds are tracked, so running it again is safe.
+`
+
+func main() {
+	if len(os.Args) != 2 || os.Args[1] == "-h" || os.Args[1] == "--help" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	env := os.Args[1]
+
+	cfg, err := config.Load()
+
+	// Init logger
+	logrus.SetReportCaller(false)
+	logger := logrus.WithFields(logrus.Fields{
+		"app":       "db-seed",
+		"build_tag": cfg.App.BuildTag,
+		"env":       env,
+	})
+	logger.Logger.SetLevel(logrus.InfoLevel)
+
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to load config")
+	}
+
+	seeds, err := migrations.Seeds(env)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to load seeds")
+	}
+	if len(seeds) == 0 {
+		logger.Warn("No seeds found for the environment")
+		return
+	}
+
+	// Init db connection
+	db, err := sql.Open("postgres", cfg.DB.URL)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to init db connection")
+	}
+	defer db.Close()
+
+	// check db connection
+	if err := db.Ping(); err != nil {
+		logger.WithError(err).Fatal("Failed to ping db")
+	}
+
+	n, err := seeder.New(db).Run(context.Background(), seeds...)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to apply seeds")
+	}
+
+	logger.Infof("Applied %d of %d seeds!", n, len(seeds))
+}
@@ -0,0 +1,263 @@
+package config
+
+import "time"
+
+type (
+	// Config is the application configuration.
+	// It's populated from defaults, an optional YAML file, the .env file
+	// and environment variables, in order of increasing priority.
+	Config struct {
+		App       App       `yaml:"app"`
+		HTTP      HTTP      `yaml:"http"`
+		CORS      CORS      `yaml:"cors"`
+		DB        DB        `yaml:"db"`
+		Redis     Redis     `yaml:"redis"`
+		Cache     Cache     `yaml:"cache"`
+		Session   Session   `yaml:"session"`
+		JWT       JWT       `yaml:"jwt"`
+		CSRF      CSRF      `yaml:"csrf"`
+		RateLimit RateLimit `yaml:"rate_limit"`
+		Scheduler Scheduler `yaml:"scheduler"`
+		Static    Static    `yaml:"static"`
+		Health    Health    `yaml:"health"`
+		Metrics   Metrics   `yaml:"metrics"`
+		Tracing   Tracing   `yaml:"tracing"`
+		Log       Log       `yaml:"log"`
+	}
+
+	// App is the general application configuration.
+	// ShutdownTimeout is the time given to every background component, e.g. a worker, to stop.
+	App struct {
+		Name            string        `yaml:"name" env:"APP_NAME"`
+		Debug           bool          `yaml:"debug" env:"APP_DEBUG"`
+		LogLevel        string        `yaml:"log_level" env:"APP_LOG_LEVEL"`
+		BuildTag        string        `yaml:"build_tag" env:"COMMIT_HASH"`
+		ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"APP_SHUTDOWN_TIMEOUT"`
+	}
+
+	// HTTP is the HTTP server configuration.
+	// AdminPort is an optional port of the internal admin server, e.g. for metrics, 0 disables it.
+	// AdminToken is the bearer token of the admin endpoints, they are disabled if it's empty.
+	// DrainDelay is the time between failing the readiness probe and closing the listener on shutdown,
+	// so load balancers stop routing new requests to the instance.
+	HTTP struct {
+		Port              int           `yaml:"port" env:"HTTP_PORT"`
+		RequestTimeout    time.Duration `yaml:"request_timeout" env:"HTTP_REQUEST_TIMEOUT"`
+		ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"HTTP_SERVER_SHUTDOWN_TIMEOUT"`
+		DrainDelay        time.Duration `yaml:"drain_delay" env:"HTTP_SERVER_DRAIN_DELAY"`
+		AdminPort         int           `yaml:"admin_port" env:"HTTP_ADMIN_PORT"`
+		AdminToken        string        `yaml:"admin_token" env:"HTTP_ADMIN_TOKEN" secret:"true"`
+		AllowContentTypes []string      `yaml:"allow_content_types" env:"ALLOW_CONTENT_TYPES"`
+	}
+
+	// CORS is the default CORS policy.
+	// Origins support subdomain wildcards and regular expressions, see pkg/cors.
+	CORS struct {
+		AllowedOrigins   []string `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+		AllowedMethods   []string `yaml:"allowed_methods" env:"CORS_ALLOWED_METHODS"`
+		AllowedHeaders   []string `yaml:"allowed_headers" env:"CORS_ALLOWED_HEADERS"`
+		ExposedHeaders   []string `yaml:"exposed_headers" env:"CORS_EXPOSED_HEADERS"`
+		AllowCredentials bool     `yaml:"allow_credentials" env:"CORS_ALLOWED_CREDENTIALS"`
+		MaxAge           int      `yaml:"max_age" env:"CORS_MAX_AGE"`
+	}
+
+	// DB is the database configuration.
+	DB struct {
+		URL          string `yaml:"url" env:"DATABASE_URL" secret:"url"`
+		MaxOpenConns int    `yaml:"max_open_conns" env:"DATABASE_MAX_OPEN_CONNS"`
+		MaxIdleConns int    `yaml:"max_idle_conns" env:"DATABASE_IDLE_CONNS"`
+
+		// MigrationsDir overrides the embedded migrations with the files from the directory.
+		MigrationsDir   string `yaml:"migrations_dir" env:"DATABASE_MIGRATIONS_DIR"`
+		MigrationsTable string `yaml:"migrations_table" env:"DATABASE_MIGRATIONS_TABLE"`
+		// MigrationsLockTimeout is the time to wait for another instance applying migrations.
+		MigrationsLockTimeout time.Duration `yaml:"migrations_lock_timeout" env:"DATABASE_MIGRATIONS_LOCK_TIMEOUT"`
+		// MigrationsVerify makes migrate up fail if applied migrations differ from the source.
+		MigrationsVerify bool `yaml:"migrations_verify" env:"DATABASE_MIGRATIONS_VERIFY"`
+	}
+
+	// Redis is the Redis configuration.
+	// Redis is optional, in-memory backends are used if the URL is not set.
+	Redis struct {
+		URL string `yaml:"url" env:"REDIS_URL" secret:"url"`
+	}
+
+	// Cache is the cache configuration.
+	Cache struct {
+		KeyPrefix  string `yaml:"key_prefix" env:"CACHE_KEY_PREFIX"`
+		MemorySize int    `yaml:"memory_size" env:"CACHE_MEMORY_SIZE"`
+	}
+
+	// Session is the session configuration.
+	Session struct {
+		Enabled       bool          `yaml:"enabled" env:"SESSION_ENABLED"`
+		Store         string        `yaml:"store" env:"SESSION_STORE"`
+		Secret        string        `yaml:"secret" env:"SESSION_SECRET" secret:"true"`
+		EncryptionKey string        `yaml:"encryption_key" env:"SESSION_ENCRYPTION_KEY" secret:"true"`
+		CookieName    string        `yaml:"cookie_name" env:"SESSION_COOKIE_NAME"`
+		CookieDomain  string        `yaml:"cookie_domain" env:"SESSION_COOKIE_DOMAIN"`
+		CookieSecure  bool          `yaml:"cookie_secure" env:"SESSION_COOKIE_SECURE"`
+		IdleTimeout   time.Duration `yaml:"idle_timeout" env:"SESSION_IDLE_TIMEOUT"`
+		Lifetime      time.Duration `yaml:"lifetime" env:"SESSION_LIFETIME"`
+	}
+
+	// JWT is the configuration of the JWT interactor.
+	// The interactor is provided to the application modules only if the signing key is set.
+	JWT struct {
+		SigningKey string        `yaml:"signing_key" env:"JWT_SIGNING_KEY" secret:"true"`
+		Issuer     string        `yaml:"issuer" env:"JWT_ISSUER"`
+		TTL        time.Duration `yaml:"ttl" env:"JWT_TTL"`
+	}
+
+	// CSRF is the CSRF protection configuration.
+	CSRF struct {
+		Enabled        bool     `yaml:"enabled" env:"CSRF_ENABLED"`
+		TrustedOrigins []string `yaml:"trusted_origins" env:"CSRF_TRUSTED_ORIGINS"`
+		CookieSecure   bool     `yaml:"cookie_secure" env:"CSRF_COOKIE_SECURE"`
+	}
+
+	// RateLimit is the default rate limit configuration.
+	RateLimit struct {
+		Enabled   bool          `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+		Algorithm string        `yaml:"algorithm" env:"RATE_LIMIT_ALGORITHM"`
+		Requests  int           `yaml:"requests" env:"RATE_LIMIT_REQUESTS"`
+		Period    time.Duration `yaml:"period" env:"RATE_LIMIT_PERIOD"`
+		Burst     int           `yaml:"burst" env:"RATE_LIMIT_BURST"`
+	}
+
+	// Scheduler is the periodic jobs scheduler configuration.
+	Scheduler struct {
+		Enabled    bool          `yaml:"enabled" env:"SCHEDULER_ENABLED"`
+		Timezone   string        `yaml:"timezone" env:"SCHEDULER_TIMEZONE"`
+		JobTimeout time.Duration `yaml:"job_timeout" env:"SCHEDULER_JOB_TIMEOUT"`
+	}
+
+	// Health is the health checks configuration.
+	Health struct {
+		Timeout  time.Duration `yaml:"timeout" env:"HEALTH_CHECK_TIMEOUT"`
+		CacheTTL time.Duration `yaml:"cache_ttl" env:"HEALTH_CHECK_CACHE_TTL"`
+	}
+
+	// Metrics is the Prometheus metrics configuration.
+	// Metrics are served on the admin port if it's set, or on the main port otherwise.
+	Metrics struct {
+		Enabled   bool   `yaml:"enabled" env:"METRICS_ENABLED"`
+		Path      string `yaml:"path" env:"METRICS_PATH"`
+		Namespace string `yaml:"namespace" env:"METRICS_NAMESPACE"`
+	}
+
+	// Tracing is the OpenTelemetry tracing configuration.
+	// The OTLP exporter is configured with standard OTEL_EXPORTER_OTLP_* env variables.
+	Tracing struct {
+		Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER"`
+		File        string  `yaml:"file" env:"TRACING_FILE"`
+		SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+	}
+
+	// Log is the logging configuration.
+	Log struct {
+		Format             string   `yaml:"format" env:"LOG_FORMAT"`
+		ReportCaller       bool     `yaml:"report_caller" env:"LOG_REPORT_CALLER"`
+		AccessEnabled      bool     `yaml:"access_enabled" env:"LOG_ACCESS_ENABLED"`
+		AccessSampleRate   float64  `yaml:"access_sample_rate" env:"LOG_ACCESS_SAMPLE_RATE"`
+		AccessExcludePaths []string `yaml:"access_exclude_paths" env:"LOG_ACCESS_EXCLUDE_PATHS"`
+	}
+
+	// Static is the static files server configuration.
+	Static struct {
+		Enabled bool   `yaml:"enabled" env:"STATIC_FILES_ENABLED"`
+		Dir     string `yaml:"dir" env:"STATIC_FILES_DIR"`
+		Prefix  string `yaml:"prefix" env:"STATIC_FILES_PREFIX"`
+	}
+)
+
+// Default returns the configuration with default values.
+func Default() *Config {
+	return &Config{
+		App: App{
+			Name:            "go-app",
+			LogLevel:        "info",
+			BuildTag:        "undefined",
+			ShutdownTimeout: 30 * time.Second,
+		},
+		HTTP: HTTP{
+			Port:              8080,
+			RequestTimeout:    10 * time.Second,
+			ShutdownTimeout:   15 * time.Second,
+			AllowContentTypes: []string{"application/json"},
+		},
+		CORS: CORS{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "HEAD"},
+			AllowedHeaders: []string{
+				"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Request-ID", "X-Request-Id",
+				"Origin", "User-Agent", "Accept-Encoding", "Accept-Language", "Cache-Control", "Connection",
+				"DNT", "Host", "Pragma", "Referer",
+			},
+			ExposedHeaders: []string{
+				"X-Request-Id", "X-CSRF-Token", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After",
+			},
+			AllowCredentials: false,
+			MaxAge:           300,
+		},
+		DB: DB{
+			MaxOpenConns: 20,
+			MaxIdleConns: 2,
+
+			MigrationsTable:       "migrations",
+			MigrationsLockTimeout: 5 * time.Minute,
+		},
+		Cache: Cache{
+			KeyPrefix:  "cache:",
+			MemorySize: 10000,
+		},
+		Session: Session{
+			Store:        "cookie",
+			CookieName:   "sid",
+			CookieSecure: true,
+			IdleTimeout:  30 * time.Minute,
+			Lifetime:     24 * time.Hour,
+		},
+		JWT: JWT{
+			Issuer: "go-app",
+			TTL:    time.Hour,
+		},
+		CSRF: CSRF{
+			CookieSecure: true,
+		},
+		RateLimit: RateLimit{
+			Enabled:   true,
+			Algorithm: "token_bucket",
+			Requests:  100,
+			Period:    time.Minute,
+		},
+		Scheduler: Scheduler{
+			Enabled:    true,
+			Timezone:   "UTC",
+			JobTimeout: 5 * time.Minute,
+		},
+		Health: Health{
+			Timeout:  2 * time.Second,
+			CacheTTL: time.Second,
+		},
+		Metrics: Metrics{
+			Enabled: true,
+			Path:    "/metrics",
+		},
+		Tracing: Tracing{
+			Exporter:    "none",
+			File:        "./traces.json",
+			SampleRatio: 1,
+		},
+		Log: Log{
+			Format:             "json",
+			AccessEnabled:      true,
+			AccessSampleRate:   1,
+			AccessExcludePaths: []string{"/health", "/livez", "/readyz", "/startupz", "/metrics"},
+		},
+		Static: Static{
+			Dir:    "./public",
+			Prefix: "/static/",
+		},
+	}
+}
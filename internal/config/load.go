@@ -0,0 +1,149 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv is the environment variable with a path to an optional YAML config file.
+const FileEnv = "CONFIG_FILE"
+
+// Load returns the validated configuration.
+// Values are taken from defaults, then the YAML file from CONFIG_FILE env var (if set),
+// then the .env file (if exists) and environment variables.
+// The configuration is returned even if it's invalid, so it can be reported.
+func Load() (*Config, error) {
+	cfg := Default()
+
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return cfg, fmt.Errorf("failed to load .env file: %w", err)
+	}
+
+	if path := os.Getenv(FileEnv); path != "" {
+		if err := cfg.LoadFile(path); err != nil {
+			return cfg, err
+		}
+	}
+
+	if err := cfg.LoadEnv(os.LookupEnv); err != nil {
+		return cfg, err
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// LoadFile overrides the configuration with values from the YAML file.
+// Unknown fields are rejected to catch typos.
+func (c *Config) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// LoadEnv overrides the configuration with values returned by lookup
+// for the names from the `env` struct tags, e.g. os.LookupEnv.
+// Lists are comma-separated. All malformed values are reported at once.
+func (c *Config) LoadEnv(lookup func(key string) (string, bool)) error {
+	var errs ValidationError
+	walk(reflect.ValueOf(c).Elem(), "", func(_ string, field reflect.StructField, v reflect.Value) {
+		key := field.Tag.Get("env")
+		if key == "" {
+			return
+		}
+		raw, ok := lookup(key)
+		if !ok {
+			return
+		}
+		if err := setValue(v, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	})
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// walk calls fn for each leaf field of the struct value,
+// path is the dot-separated list of yaml names.
+func walk(v reflect.Value, prefix string, fn func(path string, field reflect.StructField, v reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		path := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Duration(0)) {
+			walk(v.Field(i), path, fn)
+			continue
+		}
+		fn(path, field, v.Field(i))
+	}
+}
+
+// setValue parses raw string into the value according to its type.
+func setValue(v reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+
+	switch v.Interface().(type) {
+	case string:
+		v.SetString(raw)
+	case bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	case int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(int64(n))
+	case float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(f)
+	case time.Duration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		v.SetInt(int64(d))
+	case []string:
+		list := []string{}
+		for _, s := range strings.Split(raw, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				list = append(list, s)
+			}
+		}
+		v.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
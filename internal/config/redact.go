@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const redactedValue = "[REDACTED]"
+
+// Redacted returns the flattened configuration keyed by dot-separated yaml paths,
+// with secrets masked. Passwords are removed from URLs, other secrets are replaced entirely.
+// It's safe to log.
+func (c *Config) Redacted() map[string]interface{} {
+	fields := make(map[string]interface{})
+	walk(reflect.ValueOf(c).Elem(), "", func(path string, field reflect.StructField, v reflect.Value) {
+		value := v.Interface()
+		switch field.Tag.Get("secret") {
+		case "true":
+			if v.String() != "" {
+				value = redactedValue
+			}
+		case "url":
+			value = redactURL(v.String())
+		}
+		fields[path] = value
+	})
+	return fields
+}
+
+// Dump returns the redacted configuration as sorted "key=value" lines.
+func (c *Config) Dump() string {
+	fields := c.Redacted()
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		v := fields[k]
+		if list, ok := v.([]string); ok {
+			v = strings.Join(list, ",")
+		}
+		fmt.Fprintf(&b, "%s=%v\n", k, v)
+	}
+	return b.String()
+}
+
+// redactURL masks the password in the URL,
+// the whole value is masked if it's not an absolute URL,
+// e.g. a keyword DSN "host=localhost password=secret".
+func redactURL(s string) string {
+	if s == "" {
+		return s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return redactedValue
+	}
+	q := u.Query()
+	if q.Has("password") {
+		q.Set("password", "xxxxx")
+		u.RawQuery = q.Encode()
+	}
+	return u.Redacted()
+}
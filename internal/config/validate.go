@@ -0,0 +1,185 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dmitrymomot/go-app/pkg/cors"
+	"github.com/dmitrymomot/go-app/pkg/logger"
+	"github.com/dmitrymomot/go-app/pkg/ratelimit"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// ValidationError is a list of configuration problems.
+type ValidationError []error
+
+// Error implements the error interface.
+func (e ValidationError) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return "invalid configuration: " + strings.Join(msgs, "; ")
+}
+
+// Validate checks the configuration and returns ValidationError with all found problems.
+func (c *Config) Validate() error {
+	var errs ValidationError
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	// App
+	_, err := logrus.ParseLevel(c.App.LogLevel)
+	check(err == nil, "app.log_level: unknown level %q", c.App.LogLevel)
+	check(c.App.ShutdownTimeout > 0, "app.shutdown_timeout: must be positive")
+
+	// HTTP
+	check(c.HTTP.Port > 0 && c.HTTP.Port <= 65535, "http.port: must be between 1 and 65535, got %d", c.HTTP.Port)
+	check(c.HTTP.AdminPort >= 0 && c.HTTP.AdminPort <= 65535, "http.admin_port: must be between 1 and 65535, or 0 to disable, got %d", c.HTTP.AdminPort)
+	check(c.HTTP.AdminPort != c.HTTP.Port, "http.admin_port: must differ from http.port")
+	check(c.HTTP.AdminToken == "" || len(c.HTTP.AdminToken) >= 16, "http.admin_token: must be at least 16 bytes")
+	check(c.HTTP.RequestTimeout > 0, "http.request_timeout: must be positive")
+	check(c.HTTP.ShutdownTimeout > 0, "http.shutdown_timeout: must be positive")
+	check(c.HTTP.DrainDelay >= 0, "http.drain_delay: must not be negative")
+
+	// CORS
+	if err := c.CORS.Policy().Validate(); err != nil {
+		check(false, "cors: %s", err)
+	}
+
+	// DB
+	check(c.DB.URL != "", "db.url: is required")
+	if c.DB.URL != "" {
+		// Both postgres:// URLs and keyword DSNs, e.g. "host=localhost dbname=app", are accepted
+		_, err := pq.NewConnector(c.DB.URL)
+		check(err == nil, "db.url: must be a postgres:// URL or a keyword connection string")
+	}
+	check(c.DB.MaxOpenConns >= 0, "db.max_open_conns: must not be negative")
+	check(c.DB.MaxIdleConns >= 0, "db.max_idle_conns: must not be negative")
+	check(c.DB.MigrationsTable != "", "db.migrations_table: is required")
+	check(c.DB.MigrationsLockTimeout > 0, "db.migrations_lock_timeout: must be positive")
+
+	// Redis
+	if c.Redis.URL != "" {
+		u, err := url.Parse(c.Redis.URL)
+		check(err == nil && (u.Scheme == "redis" || u.Scheme == "rediss"), "redis.url: must be a redis:// or rediss:// URL")
+	}
+
+	// Cache
+	check(c.Cache.MemorySize > 0, "cache.memory_size: must be positive")
+
+	// Session
+	if c.Session.Enabled {
+		switch c.Session.Store {
+		case "cookie", "postgres":
+		case "redis":
+			check(c.Redis.URL != "", "session.store: redis store requires redis.url")
+		default:
+			check(false, "session.store: unknown store %q", c.Session.Store)
+		}
+		check(len(c.Session.Secret) >= 32, "session.secret: must be at least 32 bytes")
+		switch len(c.Session.EncryptionKey) {
+		case 0, 16, 24, 32:
+		default:
+			check(false, "session.encryption_key: must be 16, 24 or 32 bytes")
+		}
+		check(c.Session.CookieName != "", "session.cookie_name: is required")
+		check(c.Session.IdleTimeout > 0, "session.idle_timeout: must be positive")
+		check(c.Session.Lifetime > 0, "session.lifetime: must be positive")
+	}
+
+	// JWT
+	if c.JWT.SigningKey != "" {
+		check(len(c.JWT.SigningKey) >= 32, "jwt.signing_key: must be at least 32 bytes")
+		check(c.JWT.TTL > 0, "jwt.ttl: must be positive")
+	}
+
+	// CSRF
+	if c.CSRF.Enabled {
+		for _, origin := range c.CSRF.TrustedOrigins {
+			check(isHTTPURL(origin), "csrf.trusted_origins: invalid origin %q", origin)
+		}
+	}
+
+	// Rate limiting
+	if c.RateLimit.Enabled {
+		if err := c.RateLimit.Limit().Validate(); err != nil {
+			check(false, "rate_limit: %s", err)
+		}
+	}
+
+	// Scheduler
+	if c.Scheduler.Enabled {
+		_, err := time.LoadLocation(c.Scheduler.Timezone)
+		check(err == nil, "scheduler.timezone: unknown time zone %q", c.Scheduler.Timezone)
+		check(c.Scheduler.JobTimeout > 0, "scheduler.job_timeout: must be positive")
+	}
+
+	// Health checks
+	check(c.Health.Timeout > 0, "health.timeout: must be positive")
+
+	// Metrics
+	if c.Metrics.Enabled {
+		check(strings.HasPrefix(c.Metrics.Path, "/"), "metrics.path: must start with /")
+	}
+
+	// Tracing
+	switch c.Tracing.Exporter {
+	case "none", "otlp", "stdout":
+	case "file":
+		check(c.Tracing.File != "", "tracing.file: is required for the file exporter")
+	default:
+		check(false, "tracing.exporter: unknown exporter %q", c.Tracing.Exporter)
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio: must be between 0 and 1")
+
+	// Logging
+	if _, err := logger.NewFormatter(c.Log.Format); err != nil {
+		check(false, "log.format: %s", err)
+	}
+	check(c.Log.AccessSampleRate >= 0 && c.Log.AccessSampleRate <= 1, "log.access_sample_rate: must be between 0 and 1")
+
+	// Static files
+	if c.Static.Enabled {
+		check(strings.HasPrefix(c.Static.Prefix, "/"), "static.prefix: must start with /")
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Limit returns the default rate limit.
+func (r RateLimit) Limit() ratelimit.Limit {
+	return ratelimit.Limit{
+		Algorithm: ratelimit.Algorithm(r.Algorithm),
+		Requests:  r.Requests,
+		Period:    r.Period,
+		Burst:     r.Burst,
+	}
+}
+
+// Policy returns the default CORS policy.
+func (c CORS) Policy() cors.Policy {
+	return cors.Policy{
+		AllowedOrigins:   c.AllowedOrigins,
+		AllowedMethods:   c.AllowedMethods,
+		AllowedHeaders:   c.AllowedHeaders,
+		ExposedHeaders:   c.ExposedHeaders,
+		AllowCredentials: c.AllowCredentials,
+		MaxAge:           c.MaxAge,
+	}
+}
+
+// isHTTPURL reports whether s is an absolute http(s) URL.
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
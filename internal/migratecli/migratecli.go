@@ -0,0 +1,347 @@
+// Package migratecli implements the migrate command line shared by cmd/migrate and cmd/app.
+package migratecli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/dmitrymomot/go-app/internal/config"
+	"github.com/dmitrymomot/go-app/migrations"
+	"github.com/dmitrymomot/go-app/pkg/migrator"
+	"github.com/dmitrymomot/go-app/pkg/pglock"
+	"github.com/dmitrymomot/go-app/pkg/pgschema"
+	_ "github.com/lib/pq" // init pg driver
+	migrate "github.com/rubenv/sql-migrate"
+	"github.com/sirupsen/logrus"
+)
+
+// Usage is the help text of the migrate command line.
+const Usage = `
+Usage: migrate [--dry-run] <command> [arguments]
+
+Commands:
+  up [N]          apply all or N pending migrations (default command)
+  down [N]        revert the last N applied migrations (default 1)
+  redo            revert and apply again the last applied migration
+  status          print applied and pending migrations
+  to <version>    migrate up or down to the given version, 0 reverts all
+  verify          check that applied migrations match the source
+  lint            report risky operations in pending migrations
+  schema dump     write the schema snapshot of the database to the file
+  schema diff     compare the database schema with the snapshot file
+  new <name>      create a new migration file, no database is needed
+
+Flags:
+  --dry-run       print the SQL instead of executing it
+  --verify        verify applied migrations before running up
+  --module <name> create the migration in internal/<name>/repository
+  --file <path>   schema snapshot file (default migrations/schema.txt)
+`
+
+// defaultSchemaFile is the checked-in schema snapshot.
+const defaultSchemaFile = "migrations/schema.txt"
+
+// moduleNameRegexp matches valid module names, i.e. package names under internal.
+var moduleNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Command is a parsed migrate subcommand.
+type Command struct {
+	name    string
+	n       int
+	version int64
+	dryRun  bool
+	verify  bool
+
+	// new command
+	migration string
+	module    string
+
+	// schema command
+	schemaAction string
+	schemaFile   string
+}
+
+// Parse parses the command line arguments.
+// Flags are accepted anywhere, before and after the command name and arguments.
+func Parse(args []string) (Command, error) {
+	cmd := Command{name: "up"}
+
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&cmd.dryRun, "dry-run", false, "print the SQL instead of executing it")
+	fs.BoolVar(&cmd.verify, "verify", false, "verify applied migrations before running up")
+	fs.StringVar(&cmd.module, "module", "", "module to create the migration in")
+	fs.StringVar(&cmd.schemaFile, "file", defaultSchemaFile, "schema snapshot file")
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return cmd, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	var rest []string
+	if len(positional) > 0 {
+		cmd.name, rest = positional[0], positional[1:]
+	}
+	if cmd.module != "" && cmd.name != "new" {
+		return cmd, fmt.Errorf("%s: --module is only supported by the new command", cmd.name)
+	}
+	if cmd.schemaFile == "" {
+		cmd.schemaFile = defaultSchemaFile
+	}
+
+	switch cmd.name {
+	case "up", "down":
+		if cmd.name == "down" {
+			cmd.n = 1
+		}
+		if len(rest) > 1 {
+			return cmd, fmt.Errorf("%s: too many arguments", cmd.name)
+		}
+		if len(rest) == 1 {
+			n, err := strconv.Atoi(rest[0])
+			if err != nil || n < 1 {
+				return cmd, fmt.Errorf("%s: N must be a positive number, got %q", cmd.name, rest[0])
+			}
+			cmd.n = n
+		}
+	case "to":
+		if len(rest) != 1 {
+			return cmd, errors.New("to: version is required")
+		}
+		if rest[0] != "0" {
+			v, err := migrator.ParseVersion(rest[0])
+			if err != nil {
+				return cmd, fmt.Errorf("to: %w", err)
+			}
+			cmd.version = v
+		}
+	case "new":
+		if len(rest) != 1 {
+			return cmd, errors.New("new: migration name is required")
+		}
+		cmd.migration = rest[0]
+	case "schema":
+		if len(rest) != 1 || (rest[0] != "dump" && rest[0] != "diff") {
+			return cmd, errors.New("schema: dump or diff is required")
+		}
+		cmd.schemaAction = rest[0]
+	case "redo", "status", "verify", "lint":
+		if len(rest) > 0 {
+			return cmd, fmt.Errorf("%s: unexpected arguments", cmd.name)
+		}
+	default:
+		return cmd, fmt.Errorf("unknown command %q", cmd.name)
+	}
+
+	return cmd, nil
+}
+
+// NeedsConfig reports whether the command needs the application config and the database.
+// New migrations are created from the project root without them.
+func (c Command) NeedsConfig() bool {
+	return c.name != "new"
+}
+
+// Run executes the command.
+func (c Command) Run(logger logrus.FieldLogger, cfg *config.Config) error {
+	if !c.NeedsConfig() {
+		return c.runNew(logger)
+	}
+
+	c.verify = c.verify || cfg.DB.MigrationsVerify
+
+	// Init db connection
+	db, err := sql.Open("postgres", cfg.DB.URL)
+	if err != nil {
+		return fmt.Errorf("failed to init db connection: %w", err)
+	}
+	defer db.Close()
+
+	// check db connection
+	if err := db.Ping(); err != nil {
+		return fmt.Errorf("failed to ping db: %w", err)
+	}
+
+	if c.name == "schema" {
+		return c.runSchema(logger, db, cfg.DB.MigrationsTable)
+	}
+
+	// Several instances may be started at once, the lock makes them apply
+	// migrations one by one, so the others find nothing to apply.
+	return c.runMigrator(logger, db, migrations.Source(cfg.DB.MigrationsDir),
+		migrator.WithTableName(cfg.DB.MigrationsTable),
+		migrator.WithLock(pglock.NewLocker(db), cfg.DB.MigrationsLockTimeout),
+	)
+}
+
+// runMigrator executes the migrator command.
+func (c Command) runMigrator(logger logrus.FieldLogger, db *sql.DB, source migrate.MigrationSource, opts ...migrator.Option) error {
+	if c.dryRun {
+		opts = append(opts, migrator.WithDryRun(os.Stdout))
+	}
+	if c.verify {
+		opts = append(opts, migrator.WithVerify())
+	}
+	m := migrator.New(db, source, opts...)
+
+	switch c.name {
+	case "up":
+		n, err := m.Up(c.n)
+		if err != nil {
+			return err
+		}
+		c.logResult(logger, migrate.Up, n)
+	case "down":
+		n, err := m.Down(c.n)
+		if err != nil {
+			return err
+		}
+		c.logResult(logger, migrate.Down, n)
+	case "redo":
+		if err := m.Redo(); err != nil {
+			return err
+		}
+		if !c.dryRun {
+			logger.Info("Redone the last migration!")
+		}
+	case "to":
+		dir, n, err := m.To(c.version)
+		if err != nil {
+			return err
+		}
+		c.logResult(logger, dir, n)
+	case "status":
+		statuses, err := m.Status()
+		if err != nil {
+			return err
+		}
+		return migrator.WriteStatus(os.Stdout, statuses)
+	case "verify":
+		if err := m.Verify(); err != nil {
+			if verr, ok := err.(migrator.VerifyError); ok {
+				for _, e := range verr {
+					fmt.Fprintf(os.Stderr, "  - %s\n", e)
+				}
+			}
+			return err
+		}
+		logger.Info("Applied migrations match the source")
+	case "lint":
+		issues, err := m.Lint()
+		if err != nil {
+			return err
+		}
+		for _, issue := range issues {
+			fmt.Fprintln(os.Stdout, issue)
+		}
+		if len(issues) > 0 {
+			return fmt.Errorf("%d issues found in pending migrations", len(issues))
+		}
+		logger.Info("No issues found in pending migrations")
+	}
+
+	return nil
+}
+
+// logResult logs the number of applied or reverted migrations.
+func (c Command) logResult(logger logrus.FieldLogger, dir migrate.MigrationDirection, n int) {
+	switch {
+	case c.dryRun:
+		logger.Infof("Dry run: %d migrations planned", n)
+	case n == 0:
+		logger.Info("Migrations are already applied, nothing to do")
+	case dir == migrate.Down:
+		logger.Infof("Reverted %d migrations!", n)
+	default:
+		logger.Infof("Applied %d migrations!", n)
+	}
+}
+
+// migrationsDir returns the migrations directory of the module's repository package.
+// The root repository package is used if the module is empty.
+func migrationsDir(module string) (string, error) {
+	if module == "" {
+		return filepath.Join("internal", "repository", "sql", "migrations"), nil
+	}
+	if !moduleNameRegexp.MatchString(module) {
+		return "", fmt.Errorf("invalid module name %q", module)
+	}
+	return filepath.Join("internal", module, "repository", "sql", "migrations"), nil
+}
+
+// runNew creates a new migration file in the module's migrations directory.
+func (c Command) runNew(logger logrus.FieldLogger) error {
+	dir, err := migrationsDir(c.module)
+	if err != nil {
+		return err
+	}
+
+	_, statErr := os.Stat(dir)
+	path, err := migrator.Create(dir, c.migration, time.Now())
+	if err != nil {
+		return err
+	}
+	logger.Infof("Created migration %s", path)
+
+	if os.IsNotExist(statErr) {
+		logger.Warnf("New migrations directory, embed it in the %s repository package "+
+			"and add it to migrations/migrations.go", c.module)
+	}
+	return nil
+}
+
+// runSchema writes the schema snapshot of the database or compares the database with it.
+// Tables of the migration and seed tools are excluded, they differ between environments.
+func (c Command) runSchema(logger logrus.FieldLogger, db *sql.DB, migrationsTable string) error {
+	schema, err := pgschema.Dump(context.Background(), db,
+		pgschema.WithExcludedTables(migrationsTable, migrationsTable+"_checksums", "seeds"),
+	)
+	if err != nil {
+		return err
+	}
+
+	if c.schemaAction == "dump" {
+		if err := os.WriteFile(c.schemaFile, []byte(schema.String()), 0o644); err != nil {
+			return fmt.Errorf("failed to write schema snapshot: %w", err)
+		}
+		logger.Infof("Schema snapshot written to %s", c.schemaFile)
+		return nil
+	}
+
+	f, err := os.Open(c.schemaFile)
+	if err != nil {
+		return fmt.Errorf("failed to open schema snapshot: %w", err)
+	}
+	defer f.Close()
+
+	expected, err := pgschema.Parse(f)
+	if err != nil {
+		return err
+	}
+
+	diff := pgschema.Diff(expected, schema)
+	for _, l := range diff {
+		fmt.Fprintln(os.Stdout, l)
+	}
+	if len(diff) > 0 {
+		return fmt.Errorf("database schema differs from %s, run 'migrate schema dump' and commit it", c.schemaFile)
+	}
+	logger.Infof("Database schema matches %s", c.schemaFile)
+	return nil
+}
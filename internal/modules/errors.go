@@ -0,0 +1,9 @@
+package modules
+
+import "errors"
+
+// Predefined errors.
+var (
+	ErrInvalidModule   = errors.New("module must have a name")
+	ErrDuplicateModule = errors.New("module with the same name is already registered")
+)
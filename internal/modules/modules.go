@@ -0,0 +1,66 @@
+// Package modules wires the application modules: every module provides
+// the constructors of its services to the DI container and mounts its routes.
+package modules
+
+import (
+	"fmt"
+
+	"github.com/dmitrymomot/go-app/pkg/di"
+	"github.com/go-chi/chi/v5"
+)
+
+// Module is a part of the application, e.g. users or billing.
+// Both functions are optional.
+type Module struct {
+	Name string
+	// Provide registers the constructors of the module services,
+	// they resolve their dependencies from the container, see pkg/di.
+	Provide func(c *di.Container) error
+	// Routes mounts the HTTP handlers of the module.
+	// It's called after all constructors are resolved.
+	Routes func(c *di.Container, r chi.Router) error
+}
+
+// List is the list of the application modules.
+// Add new modules here, main.go doesn't need to be changed, e.g.:
+//
+//	var List = []Module{users.Module}
+var List = []Module{}
+
+// Provide registers the constructors of the modules and resolves all of them,
+// so missing dependencies, cycles and constructor errors fail the startup.
+// The shared dependencies, e.g. *sql.DB, must be provided before.
+func Provide(c *di.Container, modules ...Module) error {
+	names := make(map[string]struct{}, len(modules))
+	for _, m := range modules {
+		if m.Name == "" {
+			return ErrInvalidModule
+		}
+		if _, ok := names[m.Name]; ok {
+			return fmt.Errorf("%w: %s", ErrDuplicateModule, m.Name)
+		}
+		names[m.Name] = struct{}{}
+
+		if m.Provide == nil {
+			continue
+		}
+		if err := m.Provide(c); err != nil {
+			return fmt.Errorf("module %s: %w", m.Name, err)
+		}
+	}
+
+	return c.ResolveAll()
+}
+
+// Routes mounts the routes of the modules.
+func Routes(c *di.Container, r chi.Router, modules ...Module) error {
+	for _, m := range modules {
+		if m.Routes == nil {
+			continue
+		}
+		if err := m.Routes(c, r); err != nil {
+			return fmt.Errorf("module %s: failed to mount routes: %w", m.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package modules_test
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dmitrymomot/go-app/internal/modules"
+	"github.com/dmitrymomot/go-app/pkg/di"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// greeter is a service of the test module.
+type greeter struct{ greeting string }
+
+// greeterModule provides the greeter and mounts its route.
+var greeterModule = modules.Module{
+	Name: "greeter",
+	Provide: func(c *di.Container) error {
+		return di.Provide(c, func(c *di.Container) (*greeter, error) {
+			greeting, err := di.Resolve[string](c)
+			if err != nil {
+				return nil, err
+			}
+			return &greeter{greeting: greeting}, nil
+		})
+	},
+	Routes: func(c *di.Container, r chi.Router) error {
+		g, err := di.Resolve[*greeter](c)
+		if err != nil {
+			return err
+		}
+		r.Get("/greet", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, g.greeting)
+		})
+		return nil
+	},
+}
+
+func TestProvide(t *testing.T) {
+	// Test case 1: module services are resolved with the shared dependencies
+	t.Run("resolves module services", func(t *testing.T) {
+		c := di.New()
+		require.NoError(t, di.Supply(c, "hello"))
+		require.NoError(t, modules.Provide(c, greeterModule))
+
+		r := chi.NewRouter()
+		require.NoError(t, modules.Routes(c, r, greeterModule))
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/greet", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "hello", rec.Body.String())
+	})
+
+	// Test case 2: missing dependency fails the startup
+	t.Run("missing dependency", func(t *testing.T) {
+		err := modules.Provide(di.New(), greeterModule)
+		require.ErrorIs(t, err, di.ErrNotProvided)
+		require.Contains(t, err.Error(), "*modules_test.greeter -> string")
+	})
+
+	// Test case 3: invalid and duplicate modules
+	t.Run("invalid modules", func(t *testing.T) {
+		require.ErrorIs(t, modules.Provide(di.New(), modules.Module{}), modules.ErrInvalidModule)
+		require.ErrorIs(t, modules.Provide(di.New(), greeterModule, greeterModule), modules.ErrDuplicateModule)
+	})
+
+	// Test case 4: provide error names the module
+	t.Run("provide error", func(t *testing.T) {
+		c := di.New()
+		failing := modules.Module{
+			Name: "billing",
+			Provide: func(c *di.Container) error {
+				return di.Supply[*sql.DB](c, nil)
+			},
+		}
+		require.NoError(t, di.Supply[*sql.DB](c, nil))
+
+		err := modules.Provide(c, failing)
+		require.ErrorIs(t, err, di.ErrAlreadyProvided)
+		require.Contains(t, err.Error(), "module billing")
+	})
+}
+
+func TestRoutes(t *testing.T) {
+	// Test case 1: routes error names the module
+	t.Run("routes error", func(t *testing.T) {
+		failure := errors.New("no handler")
+		err := modules.Routes(di.New(), chi.NewRouter(), modules.Module{
+			Name:   "users",
+			Routes: func(*di.Container, chi.Router) error { return failure },
+		})
+		require.ErrorIs(t, err, failure)
+		require.Contains(t, err.Error(), "module users")
+	})
+}
@@ -0,0 +1,9 @@
+package repository
+
+import "embed"
+
+// Migrations contains the SQL migrations of the repository,
+// they are collected by the migrations package.
+//
+//go:embed sql/migrations/*.sql
+var Migrations embed.FS
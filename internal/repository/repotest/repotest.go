@@ -0,0 +1,143 @@
+// Package repotest provisions isolated Postgres schemas with all migrations applied
+// for repository tests, so sqlc queries can be tested in parallel against a real database.
+//
+// Tests are skipped if TEST_DATABASE_URL is not set.
+package repotest
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmitrymomot/go-app/internal/repository"
+	"github.com/dmitrymomot/go-app/migrations"
+	"github.com/dmitrymomot/go-app/pkg/migrator"
+	"github.com/dmitrymomot/go-app/pkg/pglock"
+	"github.com/lib/pq"
+)
+
+// DatabaseURLEnv is the environment variable with the URL of the test database.
+const DatabaseURLEnv = "TEST_DATABASE_URL"
+
+// Extensions are the Postgres extensions created by the migrations.
+// They are shared by all schemas of the database, so they are installed into the public
+// schema once, otherwise the migrations would create them in the test schema and
+// they would be dropped together with it.
+var Extensions = []string{"uuid-ossp"}
+
+// DB returns a connection pool bound to a new schema with all embedded migrations applied.
+// The schema is dropped when the test and its subtests complete.
+func DB(t testing.TB) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv(DatabaseURLEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set, skipping the database test", DatabaseURLEnv)
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("repotest: failed to open database: %v", err)
+	}
+	t.Cleanup(func() { admin.Close() }) // nolint:errcheck
+
+	createExtensions(t, admin)
+
+	schema := schemaName(t)
+	if _, err := admin.Exec("CREATE SCHEMA " + pq.QuoteIdentifier(schema)); err != nil {
+		t.Fatalf("repotest: failed to create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + pq.QuoteIdentifier(schema) + " CASCADE"); err != nil {
+			t.Errorf("repotest: failed to drop schema %s: %v", schema, err)
+		}
+	})
+
+	db, err := sql.Open("postgres", withSearchPath(dsn, schema))
+	if err != nil {
+		t.Fatalf("repotest: failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() }) // nolint:errcheck
+
+	if _, err := migrator.New(db, migrations.Source("")).Up(0); err != nil {
+		t.Fatalf("repotest: failed to apply migrations: %v", err)
+	}
+
+	return db
+}
+
+// Querier returns a querier bound to a new schema with all migrations applied.
+// Data written through it is kept until the schema is dropped at the end of the test.
+func Querier(t testing.TB) repository.TxQuerier {
+	t.Helper()
+	return repository.NewQuerier(DB(t))
+}
+
+// Tx returns a querier running in a transaction on a new schema with all migrations applied.
+// The transaction is rolled back when the test completes.
+func Tx(t testing.TB) repository.TxQuerier {
+	t.Helper()
+
+	tx, err := Querier(t).BeginTx(context.Background())
+	if err != nil {
+		t.Fatalf("repotest: failed to begin transaction: %v", err)
+	}
+	t.Cleanup(func() { tx.Rollback() }) // nolint:errcheck
+
+	return tx
+}
+
+// createExtensions installs the extensions into the public schema.
+// Parallel tests wait for each other, creating the same extension concurrently fails.
+func createExtensions(t testing.TB, db *sql.DB) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	lock, err := pglock.NewLocker(db, pglock.WithRetryInterval(50*time.Millisecond)).Lock(ctx, "repotest:extensions")
+	if err != nil {
+		t.Fatalf("repotest: %v", err)
+	}
+	defer lock.Unlock(ctx) // nolint:errcheck
+
+	for _, ext := range Extensions {
+		if _, err := db.ExecContext(ctx, "CREATE EXTENSION IF NOT EXISTS "+pq.QuoteIdentifier(ext)+" WITH SCHEMA public"); err != nil {
+			t.Fatalf("repotest: failed to create extension %s: %v", ext, err)
+		}
+	}
+}
+
+// schemaName returns a unique schema name for the test.
+func schemaName(t testing.TB) string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatalf("repotest: failed to generate schema name: %v", err)
+	}
+	return "test_" + hex.EncodeToString(b)
+}
+
+// withSearchPath returns the connection string with the schema as the search path,
+// so all unqualified objects are created and looked up in it.
+// Extensions live in the public schema, so it is kept in the path.
+func withSearchPath(dsn, schema string) string {
+	searchPath := schema + ",public"
+
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err == nil {
+			q := u.Query()
+			q.Set("search_path", searchPath)
+			u.RawQuery = q.Encode()
+			return u.String()
+		}
+	}
+
+	return dsn + " search_path='" + searchPath + "'"
+}
@@ -0,0 +1,48 @@
+package repotest_test
+
+import (
+	"testing"
+
+	"github.com/dmitrymomot/go-app/internal/repository/repotest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB(t *testing.T) {
+	t.Parallel()
+
+	// Test case 1: migrations are applied to the isolated schema
+	t.Run("migrations are applied", func(t *testing.T) {
+		t.Parallel()
+
+		db := repotest.DB(t)
+
+		var table *string
+		require.NoError(t, db.QueryRow("SELECT to_regclass('sessions')::text").Scan(&table))
+		require.NotNil(t, table)
+
+		var schema string
+		require.NoError(t, db.QueryRow("SELECT current_schema()").Scan(&schema))
+		require.Contains(t, schema, "test_")
+	})
+
+	// Test case 2: schemas of parallel tests are isolated
+	t.Run("schemas are isolated", func(t *testing.T) {
+		t.Parallel()
+
+		a, b := repotest.DB(t), repotest.DB(t)
+		_, err := a.Exec("INSERT INTO scheduler_jobs (name, last_tick_at, last_started_at, last_status) VALUES ('cleanup', NOW(), NOW(), 'running')")
+		require.NoError(t, err)
+
+		var count int
+		require.NoError(t, b.QueryRow("SELECT COUNT(*) FROM scheduler_jobs").Scan(&count))
+		require.Zero(t, count)
+	})
+}
+
+func TestTx(t *testing.T) {
+	t.Parallel()
+
+	// The transaction is rolled back on cleanup, committing it first is fine too.
+	tx := repotest.Tx(t)
+	require.NoError(t, tx.Commit())
+}
@@ -0,0 +1,17 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS scheduler_jobs (
+    name VARCHAR(255) PRIMARY KEY,
+    last_tick_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_finished_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    last_status VARCHAR(32) NOT NULL,
+    last_error TEXT DEFAULT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER update_scheduler_jobs_modtime BEFORE
+UPDATE ON scheduler_jobs FOR EACH ROW EXECUTE PROCEDURE update_updated_at_column();
+
+-- +migrate Down
+DROP TRIGGER IF EXISTS update_scheduler_jobs_modtime ON scheduler_jobs;
+DROP TABLE IF EXISTS scheduler_jobs;
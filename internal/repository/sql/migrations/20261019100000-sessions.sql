@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS sessions (
+    id VARCHAR(64) PRIMARY KEY,
+    data BYTEA NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS sessions_expires_at_idx ON sessions (expires_at);
+CREATE TRIGGER update_sessions_modtime BEFORE
+UPDATE ON sessions FOR EACH ROW EXECUTE PROCEDURE update_updated_at_column();
+
+-- +migrate Down
+DROP TRIGGER IF EXISTS update_sessions_modtime ON sessions;
+DROP TABLE IF EXISTS sessions;
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"database/sql"
+
+	"github.com/dmitrymomot/go-app/pkg/tracing"
 )
 
 type (
@@ -24,9 +26,10 @@ type (
 )
 
 // NewQuerier returns a new instance of the Querier interface implementation.
+// Every query is traced as a child span of the span from the context.
 func NewQuerier(db *sql.DB) TxQuerier {
 	return &queries{
-		Queries: New(db), // nolint:exhaustivestruct // All the queries are embedded into the struct
+		Queries: New(tracing.WrapDB(db)), // nolint:exhaustivestruct // All the queries are embedded into the struct
 		db:      db,
 	}
 }
@@ -41,7 +44,7 @@ func (q *queries) BeginTx(ctx context.Context) (TxQuerier, error) {
 		return nil, err
 	}
 	return &queries{
-		Queries: New(tx), // nolint:exhaustivestruct // All the queries are embedded into the struct
+		Queries: New(tracing.WrapDB(tx)), // nolint:exhaustivestruct // All the queries are embedded into the struct
 		tx:      tx,
 		db:      q.db,
 	}, nil
@@ -32,24 +32,26 @@ func Run() error {
 	return sh.RunV("go", "run", "./cmd/app/")
 }
 
-// PrepareMigration prepares the database migration
-func PrepareMigration() error {
-	color.Cyan("Preparing database migration...")
-	if err := sh.RunV("./scripts/prepare-migrations.sh"); err != nil {
-		// no need to return error here. It's just a warning.
-		color.Yellow("Failed to prepare database migration: %v", err) // nolint: errcheck
-	}
-	return nil
-}
-
 // MigrateUp runs the database migrations up
 func MigrateUp() error {
-	if err := PrepareMigration(); err != nil {
-		return fmt.Errorf("failed to prepare migration: %w", err)
-	}
-
 	color.Cyan("Running database migrations...")
-	return sh.RunV("go", "run", "./cmd/migrate/main.go")
+	return sh.RunV("go", "run", "./cmd/migrate/")
+}
+
+// NewMigration creates a new migration in internal/repository, e.g. mage newMigration create_users_table
+func NewMigration(name string) error {
+	return sh.RunV("go", "run", "./cmd/migrate/", "new", name)
+}
+
+// NewModuleMigration creates a new migration in internal/<module>/repository, e.g. mage newModuleMigration auth create_users_table
+func NewModuleMigration(module, name string) error {
+	return sh.RunV("go", "run", "./cmd/migrate/", "new", name, "--module", module)
+}
+
+// Seed populates the database with the seeds of the environment, e.g. mage seed dev
+func Seed(env string) error {
+	color.Cyan("Seeding the database...")
+	return sh.RunV("go", "run", "./cmd/seed/", env)
 }
 
 // Up runs the application and the database migrations up
@@ -0,0 +1,39 @@
+// Package migrations collects the SQL migrations of all modules
+// and embeds them into the binaries.
+package migrations
+
+import (
+	"embed"
+	"os"
+
+	"github.com/dmitrymomot/go-app/internal/repository"
+	"github.com/dmitrymomot/go-app/pkg/migrator"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// system contains the migrations preparing the database for the modules,
+// e.g. extensions and shared functions.
+//
+//go:embed *.sql
+var system embed.FS
+
+// modules lists the migrations of every module's repository package.
+// Add a new module here, the order doesn't matter: migrations are sorted by id.
+var modules = []migrator.FSSource{
+	{FS: system, Root: "."},
+	{FS: repository.Migrations, Root: "sql/migrations"},
+}
+
+// Source returns the migration source with the embedded migrations of all modules.
+// If dir is not empty, the migrations are read from the directory instead.
+func Source(dir string) migrate.MigrationSource {
+	if dir != "" {
+		return migrator.FSSource{FS: os.DirFS(dir), Root: "."}
+	}
+
+	sources := make([]migrate.MigrationSource, 0, len(modules))
+	for _, m := range modules {
+		sources = append(sources, m)
+	}
+	return migrator.Merge(sources...)
+}
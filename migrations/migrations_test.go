@@ -0,0 +1,33 @@
+package migrations_test
+
+import (
+	"testing"
+
+	"github.com/dmitrymomot/go-app/migrations"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSource(t *testing.T) {
+	found, err := migrations.Source("").FindMigrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, found)
+
+	// The database preparing migration must be applied first.
+	require.Equal(t, "00000000000000-db_preparing.sql", found[0].Id)
+	for i := 1; i < len(found); i++ {
+		require.True(t, found[i-1].Less(found[i]), "%s must go before %s", found[i-1].Id, found[i].Id)
+		require.NotEmpty(t, found[i].Up, found[i].Id)
+	}
+}
+
+func TestSeeds(t *testing.T) {
+	seeds, err := migrations.Seeds("dev")
+	require.NoError(t, err)
+	for _, s := range seeds {
+		require.NotNil(t, s.Run, s.ID)
+	}
+
+	seeds, err = migrations.Seeds("unknown")
+	require.NoError(t, err)
+	require.Empty(t, seeds)
+}
@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"io/fs"
+
+	"github.com/dmitrymomot/go-app/internal/repository"
+	"github.com/dmitrymomot/go-app/pkg/seeder"
+)
+
+// seeds contains the SQL seeds by environment: seeds/<env>/*.sql.
+//
+//go:embed seeds
+var seeds embed.FS
+
+// goSeeds lists the Go seeders by environment. Ids are prefixed with the environment
+// like the ids of SQL seeds, so seeds are ordered together and don't collide between environments, e.g.:
+//
+//	"dev": {QuerierSeed("dev/20261019090000-admin_user", seedAdminUser)},
+var goSeeds = map[string][]seeder.Seed{}
+
+// QuerierSeed returns the seed running fn with the repository Querier
+// bound to the seed transaction.
+func QuerierSeed(id string, fn func(ctx context.Context, q repository.Querier) error) seeder.Seed {
+	return seeder.Seed{
+		ID: id,
+		Run: func(ctx context.Context, tx *sql.Tx) error {
+			return fn(ctx, repository.New(tx))
+		},
+	}
+}
+
+// Seeds returns the SQL and Go seeds of the environment ordered by id.
+// SQL seeds are identified by their path in the seeds directory, e.g. "dev/001-users.sql",
+// because the tracking table is shared by all environments.
+func Seeds(env string) ([]seeder.Seed, error) {
+	root, err := fs.Sub(seeds, "seeds")
+	if err != nil {
+		return nil, err
+	}
+	sqlSeeds, err := seeder.FromFS(root, env)
+	if err != nil {
+		return nil, err
+	}
+	return seeder.Sort(append(sqlSeeds, goSeeds[env]...))
+}
@@ -0,0 +1,263 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+type (
+	// Cache is a typed cache on top of a Store.
+	// Concurrent loads of the same key are deduplicated,
+	// so a cache miss under load results in a single call of the loader.
+	Cache[T any] struct {
+		store        Store
+		codec        Codec
+		prefix       string
+		ttl          time.Duration
+		loadTimeout  time.Duration
+		errorHandler func(error)
+		group        singleflight.Group
+	}
+
+	// LoaderFunc loads the value on a cache miss.
+	LoaderFunc[T any] func(ctx context.Context) (T, error)
+
+	// Option is a function that configures the Cache.
+	Option func(*options)
+
+	// ItemOption is a function that configures a single cached item.
+	ItemOption func(*item)
+
+	options struct {
+		codec        Codec
+		prefix       string
+		ttl          time.Duration
+		loadTimeout  time.Duration
+		errorHandler func(error)
+	}
+
+	// detachedContext keeps the values of the parent context
+	// without its cancellation and deadline.
+	detachedContext struct{ context.Context }
+
+	item struct {
+		ttl    time.Duration
+		hasTTL bool
+		tags   []string
+	}
+)
+
+// New creates a new typed Cache instance on top of the given store.
+func New[T any](store Store, opts ...Option) *Cache[T] {
+	if store == nil {
+		panic(ErrNilStore)
+	}
+
+	o := &options{
+		codec:        JSONCodec{},
+		ttl:          time.Hour,
+		loadTimeout:  30 * time.Second,
+		errorHandler: func(error) {},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return &Cache[T]{
+		store:        store,
+		codec:        o.codec,
+		prefix:       o.prefix,
+		ttl:          o.ttl,
+		loadTimeout:  o.loadTimeout,
+		errorHandler: o.errorHandler,
+	}
+}
+
+// WithCodec sets the codec used to encode cached values.
+// Default is JSONCodec.
+func WithCodec(codec Codec) Option {
+	return func(o *options) {
+		if codec != nil {
+			o.codec = codec
+		}
+	}
+}
+
+// WithPrefix sets the prefix of all keys of the cache.
+// It is useful to share one store between several typed caches.
+func WithPrefix(prefix string) Option {
+	return func(o *options) {
+		o.prefix = prefix
+	}
+}
+
+// WithDefaultTTL sets the TTL of items stored without WithTTL.
+// Default is 1 hour.
+func WithDefaultTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+// WithLoadTimeout sets the maximum duration of a loader call of GetOrLoad.
+// Default is 30 seconds.
+func WithLoadTimeout(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.loadTimeout = d
+		}
+	}
+}
+
+// WithErrorHandler sets the function called on store errors which are not
+// returned to the caller, e.g. when GetOrLoad fails to cache a loaded value.
+func WithErrorHandler(fn func(error)) Option {
+	return func(o *options) {
+		if fn != nil {
+			o.errorHandler = fn
+		}
+	}
+}
+
+// WithTTL sets the TTL of the item. Zero TTL means the item never expires.
+func WithTTL(ttl time.Duration) ItemOption {
+	return func(i *item) {
+		i.ttl = ttl
+		i.hasTTL = true
+	}
+}
+
+// WithTags associates the item with the given tags.
+func WithTags(tags ...string) ItemOption {
+	return func(i *item) {
+		i.tags = append(i.tags, tags...)
+	}
+}
+
+// Get returns the cached value for the given key.
+// It returns ErrNotFound on a cache miss.
+func (c *Cache[T]) Get(ctx context.Context, key string) (T, error) {
+	var value T
+
+	data, err := c.store.Get(ctx, c.key(key))
+	if err != nil {
+		return value, err
+	}
+
+	if err := c.codec.Unmarshal(data, &value); err != nil {
+		return value, fmt.Errorf("cache: failed to decode value of key %q: %w", key, err)
+	}
+
+	return value, nil
+}
+
+// Set stores the value for the given key.
+func (c *Cache[T]) Set(ctx context.Context, key string, value T, opts ...ItemOption) error {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("cache: failed to encode value of key %q: %w", key, err)
+	}
+
+	i := c.item(opts...)
+	return c.store.Set(ctx, c.key(key), data, i.ttl, c.tags(i.tags)...)
+}
+
+// Delete removes the given keys.
+func (c *Cache[T]) Delete(ctx context.Context, keys ...string) error {
+	prefixed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		prefixed = append(prefixed, c.key(key))
+	}
+	return c.store.Delete(ctx, prefixed...)
+}
+
+// InvalidateTags removes all items associated with the given tags.
+func (c *Cache[T]) InvalidateTags(ctx context.Context, tags ...string) error {
+	return c.store.InvalidateTags(ctx, c.tags(tags)...)
+}
+
+// GetOrLoad returns the cached value for the given key.
+// On a cache miss it calls the loader once for all concurrent callers
+// and caches the loaded value. Store errors are passed to the error handler,
+// so a broken cache backend does not break reads.
+//
+// The loader is shared by the callers, so its context keeps the values of the
+// caller's context but not its cancellation, and is limited by the load timeout instead.
+// A canceled caller stops waiting for the loader without failing the others.
+func (c *Cache[T]) GetOrLoad(ctx context.Context, key string, loader LoaderFunc[T], opts ...ItemOption) (T, error) {
+	value, err := c.Get(ctx, key)
+	if err == nil {
+		return value, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		c.errorHandler(err)
+	}
+
+	resC := c.group.DoChan(key, func() (interface{}, error) {
+		loadCtx, cancel := context.WithTimeout(detachedContext{ctx}, c.loadTimeout)
+		defer cancel()
+
+		value, err := loader(loadCtx)
+		if err != nil {
+			return value, err
+		}
+		if err := c.Set(loadCtx, key, value, opts...); err != nil {
+			c.errorHandler(err)
+		}
+		return value, nil
+	})
+
+	var zero T
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case res := <-resC:
+		if res.Err != nil {
+			return zero, res.Err
+		}
+		result, _ := res.Val.(T)
+		return result, nil
+	}
+}
+
+// Deadline returns no deadline, the parent's one is detached.
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+// Done returns nil, the context is never canceled.
+func (detachedContext) Done() <-chan struct{} { return nil }
+
+// Err returns nil, the context is never canceled.
+func (detachedContext) Err() error { return nil }
+
+// key returns the prefixed key.
+func (c *Cache[T]) key(key string) string {
+	return c.prefix + key
+}
+
+// tags returns the prefixed tags.
+func (c *Cache[T]) tags(tags []string) []string {
+	if c.prefix == "" {
+		return tags
+	}
+	prefixed := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		prefixed = append(prefixed, c.prefix+tag)
+	}
+	return prefixed
+}
+
+// item returns the item options with defaults applied.
+func (c *Cache[T]) item(opts ...ItemOption) *item {
+	i := &item{}
+	for _, opt := range opts {
+		opt(i)
+	}
+	if !i.hasTTL {
+		i.ttl = c.ttl
+	}
+	return i
+}
@@ -0,0 +1,206 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dmitrymomot/go-app/pkg/cache"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+
+	// Test case 1: least recently used item is evicted
+	t.Run("least recently used item is evicted", func(t *testing.T) {
+		s := cache.NewMemoryStore(2)
+		require.NoError(t, s.Set(ctx, "a", []byte("a"), 0))
+		require.NoError(t, s.Set(ctx, "b", []byte("b"), 0))
+
+		_, err := s.Get(ctx, "a") // "b" becomes the least recently used item
+		require.NoError(t, err)
+		require.NoError(t, s.Set(ctx, "c", []byte("c"), 0))
+
+		_, err = s.Get(ctx, "b")
+		require.ErrorIs(t, err, cache.ErrNotFound)
+		_, err = s.Get(ctx, "a")
+		require.NoError(t, err)
+		_, err = s.Get(ctx, "c")
+		require.NoError(t, err)
+	})
+
+	// Test case 2: expired item is not returned
+	t.Run("expired item is not returned", func(t *testing.T) {
+		s := cache.NewMemoryStore(0)
+		require.NoError(t, s.Set(ctx, "a", []byte("a"), 10*time.Millisecond))
+
+		time.Sleep(20 * time.Millisecond)
+		_, err := s.Get(ctx, "a")
+		require.ErrorIs(t, err, cache.ErrNotFound)
+	})
+
+	// Test case 3: items are invalidated by tag
+	t.Run("items are invalidated by tag", func(t *testing.T) {
+		s := cache.NewMemoryStore(0)
+		require.NoError(t, s.Set(ctx, "a", []byte("a"), 0, "users"))
+		require.NoError(t, s.Set(ctx, "b", []byte("b"), 0, "users", "admins"))
+		require.NoError(t, s.Set(ctx, "c", []byte("c"), 0, "posts"))
+
+		require.NoError(t, s.InvalidateTags(ctx, "users"))
+
+		_, err := s.Get(ctx, "a")
+		require.ErrorIs(t, err, cache.ErrNotFound)
+		_, err = s.Get(ctx, "b")
+		require.ErrorIs(t, err, cache.ErrNotFound)
+		v, err := s.Get(ctx, "c")
+		require.NoError(t, err)
+		require.Equal(t, []byte("c"), v)
+	})
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+
+	// Test case 1: set, get and delete typed value
+	t.Run("set, get and delete typed value", func(t *testing.T) {
+		c := cache.New[user](cache.NewMemoryStore(0), cache.WithPrefix("users:"))
+
+		_, err := c.Get(ctx, "1")
+		require.ErrorIs(t, err, cache.ErrNotFound)
+
+		require.NoError(t, c.Set(ctx, "1", user{ID: 1, Name: "John"}, cache.WithTTL(time.Minute)))
+		u, err := c.Get(ctx, "1")
+		require.NoError(t, err)
+		require.Equal(t, user{ID: 1, Name: "John"}, u)
+
+		require.NoError(t, c.Delete(ctx, "1"))
+		_, err = c.Get(ctx, "1")
+		require.ErrorIs(t, err, cache.ErrNotFound)
+	})
+
+	// Test case 2: concurrent loads are deduplicated
+	t.Run("concurrent loads are deduplicated", func(t *testing.T) {
+		c := cache.New[user](cache.NewMemoryStore(0))
+
+		var calls int32
+		loader := func(context.Context) (user, error) {
+			atomic.AddInt32(&calls, 1)
+			time.Sleep(50 * time.Millisecond)
+			return user{ID: 1, Name: "John"}, nil
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				u, err := c.GetOrLoad(ctx, "1", loader)
+				require.NoError(t, err)
+				require.Equal(t, 1, u.ID)
+			}()
+		}
+		wg.Wait()
+
+		require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	// Test case 3: loader error is returned and not cached
+	t.Run("loader error is returned and not cached", func(t *testing.T) {
+		c := cache.New[user](cache.NewMemoryStore(0))
+		errLoad := errors.New("load failed")
+
+		_, err := c.GetOrLoad(ctx, "1", func(context.Context) (user, error) {
+			return user{}, errLoad
+		})
+		require.ErrorIs(t, err, errLoad)
+
+		_, err = c.Get(ctx, "1")
+		require.ErrorIs(t, err, cache.ErrNotFound)
+	})
+
+	// Test case 4: canceled caller doesn't cancel the shared load
+	t.Run("canceled caller doesn't cancel the shared load", func(t *testing.T) {
+		c := cache.New[user](cache.NewMemoryStore(0))
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		loader := func(ctx context.Context) (user, error) {
+			close(started)
+			select {
+			case <-release:
+				return user{ID: 1}, nil
+			case <-ctx.Done():
+				return user{}, ctx.Err()
+			}
+		}
+
+		firstCtx, cancel := context.WithCancel(ctx)
+		firstErr := make(chan error, 1)
+		go func() {
+			_, err := c.GetOrLoad(firstCtx, "1", loader)
+			firstErr <- err
+		}()
+		<-started
+
+		secondC := make(chan user, 1)
+		go func() {
+			u, err := c.GetOrLoad(ctx, "1", loader)
+			require.NoError(t, err)
+			secondC <- u
+		}()
+
+		cancel()
+		require.ErrorIs(t, <-firstErr, context.Canceled)
+
+		close(release)
+		require.Equal(t, user{ID: 1}, <-secondC)
+	})
+
+	// Test case 5: load timeout
+	t.Run("load timeout", func(t *testing.T) {
+		c := cache.New[user](cache.NewMemoryStore(0), cache.WithLoadTimeout(10*time.Millisecond))
+
+		_, err := c.GetOrLoad(ctx, "1", func(ctx context.Context) (user, error) {
+			<-ctx.Done()
+			return user{}, ctx.Err()
+		})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	// Test case 6: read-through wrapper invalidated by tag
+	t.Run("read-through wrapper invalidated by tag", func(t *testing.T) {
+		c := cache.New[user](cache.NewMemoryStore(0), cache.WithPrefix("users:"))
+
+		var calls int32
+		getUser := cache.ReadThrough(c,
+			func(id int) string { return strconv.Itoa(id) },
+			func(_ context.Context, id int) (user, error) {
+				atomic.AddInt32(&calls, 1)
+				return user{ID: id}, nil
+			},
+			cache.WithTags("users"),
+		)
+
+		for i := 0; i < 3; i++ {
+			u, err := getUser(ctx, 7)
+			require.NoError(t, err)
+			require.Equal(t, 7, u.ID)
+		}
+		require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+		require.NoError(t, c.InvalidateTags(ctx, "users"))
+		_, err := getUser(ctx, 7)
+		require.NoError(t, err)
+		require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+}
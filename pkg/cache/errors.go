@@ -0,0 +1,9 @@
+package cache
+
+import "errors"
+
+// Predefined errors.
+var (
+	ErrNotFound = errors.New("cache: key not found")
+	ErrNilStore = errors.New("cache: store must not be nil")
+)
@@ -0,0 +1,152 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type (
+	// memoryStore is an in-memory LRU implementation of the Store interface.
+	memoryStore struct {
+		mu       sync.Mutex
+		capacity int
+		items    map[string]*list.Element
+		lru      *list.List // front is the most recently used item
+		tags     map[string]map[string]struct{}
+	}
+
+	// memoryItem is a value stored in the memory store.
+	memoryItem struct {
+		key       string
+		value     []byte
+		expiresAt time.Time
+		tags      []string
+	}
+)
+
+// NewMemoryStore returns a new in-memory LRU Store instance.
+// The least recently used items are evicted when the number of items
+// exceeds the given capacity. Zero or negative capacity means unlimited.
+func NewMemoryStore(capacity int) Store {
+	return &memoryStore{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		lru:      list.New(),
+		tags:     make(map[string]map[string]struct{}),
+	}
+}
+
+// Get returns the value for the given key.
+func (s *memoryStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	item := el.Value.(*memoryItem)
+	if item.expired(time.Now()) {
+		s.remove(el)
+		return nil, ErrNotFound
+	}
+
+	s.lru.MoveToFront(el)
+	return item.value, nil
+}
+
+// Set stores the value for the given key with the given TTL.
+func (s *memoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[key]; ok {
+		s.remove(el)
+	}
+
+	item := &memoryItem{
+		key:   key,
+		value: value,
+		tags:  tags,
+	}
+	if ttl > 0 {
+		item.expiresAt = time.Now().Add(ttl)
+	}
+
+	s.items[key] = s.lru.PushFront(item)
+	for _, tag := range tags {
+		if _, ok := s.tags[tag]; !ok {
+			s.tags[tag] = make(map[string]struct{})
+		}
+		s.tags[tag][key] = struct{}{}
+	}
+
+	for s.capacity > 0 && s.lru.Len() > s.capacity {
+		s.remove(s.lru.Back())
+	}
+
+	return nil
+}
+
+// Delete removes the given keys.
+func (s *memoryStore) Delete(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := s.items[key]; ok {
+			s.remove(el)
+		}
+	}
+	return nil
+}
+
+// InvalidateTags removes all keys associated with the given tags.
+func (s *memoryStore) InvalidateTags(_ context.Context, tags ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range s.tags[tag] {
+			if el, ok := s.items[key]; ok {
+				s.remove(el)
+			}
+		}
+		delete(s.tags, tag)
+	}
+	return nil
+}
+
+// Close releases the resources used by the store.
+func (s *memoryStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items = make(map[string]*list.Element)
+	s.tags = make(map[string]map[string]struct{})
+	s.lru.Init()
+	return nil
+}
+
+// remove removes the element from the LRU list and all indexes.
+// The caller must hold the lock.
+func (s *memoryStore) remove(el *list.Element) {
+	item := s.lru.Remove(el).(*memoryItem)
+	delete(s.items, item.key)
+	for _, tag := range item.tags {
+		if keys, ok := s.tags[tag]; ok {
+			delete(keys, item.key)
+			if len(keys) == 0 {
+				delete(s.tags, tag)
+			}
+		}
+	}
+}
+
+// expired returns true if the item is expired at the given time.
+func (i *memoryItem) expired(now time.Time) bool {
+	return !i.expiresAt.IsZero() && now.After(i.expiresAt)
+}
@@ -0,0 +1,25 @@
+package cache
+
+import "context"
+
+// ReadThrough wraps the given function, e.g. a repository method, with the cache.
+// The cache key is built from the function argument with the key function.
+//
+//	getUser := cache.ReadThrough(users,
+//		func(id uuid.UUID) string { return "user:" + id.String() },
+//		repo.GetUserByID,
+//		cache.WithTTL(10*time.Minute), cache.WithTags("users"),
+//	)
+//	user, err := getUser(ctx, id)
+func ReadThrough[K any, T any](
+	c *Cache[T],
+	key func(K) string,
+	fn func(context.Context, K) (T, error),
+	opts ...ItemOption,
+) func(context.Context, K) (T, error) {
+	return func(ctx context.Context, arg K) (T, error) {
+		return c.GetOrLoad(ctx, key(arg), func(ctx context.Context) (T, error) {
+			return fn(ctx, arg)
+		}, opts...)
+	}
+}
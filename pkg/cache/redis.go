@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type (
+	// redisStore is a Redis implementation of the Store interface.
+	// Tags are stored as Redis sets of the tagged keys.
+	redisStore struct {
+		client redis.UniversalClient
+		prefix string
+	}
+
+	// RedisOption is a function that configures the Redis store.
+	RedisOption func(*redisStore)
+)
+
+// setWithTags stores the value and adds the key to the tag sets.
+// Tag sets live at least as long as the longest-living key in them.
+//
+// KEYS[1] - key, KEYS[2..n] - tag sets
+// ARGV[1] - value, ARGV[2] - TTL in milliseconds, 0 means no expiration
+var setWithTags = redis.NewScript(`
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ttl)
+else
+	redis.call("SET", KEYS[1], ARGV[1])
+end
+for i = 2, #KEYS do
+	local existed = redis.call("EXISTS", KEYS[i])
+	local current = redis.call("PTTL", KEYS[i])
+	redis.call("SADD", KEYS[i], KEYS[1])
+	if ttl == 0 then
+		redis.call("PERSIST", KEYS[i])
+	elseif existed == 0 or (current >= 0 and current < ttl) then
+		redis.call("PEXPIRE", KEYS[i], ttl)
+	end
+end
+return 1
+`)
+
+// NewRedisStore returns a new Store instance backed by Redis.
+func NewRedisStore(client redis.UniversalClient, opts ...RedisOption) Store {
+	s := &redisStore{
+		client: client,
+		prefix: "cache:",
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// WithRedisPrefix sets the prefix of all keys stored in Redis.
+// Default is "cache:".
+func WithRedisPrefix(prefix string) RedisOption {
+	return func(s *redisStore) {
+		s.prefix = prefix
+	}
+}
+
+// Get returns the value for the given key.
+func (s *redisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.client.Get(ctx, s.key(key)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("cache: failed to get key %q: %w", key, err)
+	}
+	return value, nil
+}
+
+// Set stores the value for the given key with the given TTL.
+func (s *redisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	if len(tags) == 0 {
+		if err := s.client.Set(ctx, s.key(key), value, ttl).Err(); err != nil {
+			return fmt.Errorf("cache: failed to set key %q: %w", key, err)
+		}
+		return nil
+	}
+
+	keys := make([]string, 0, len(tags)+1)
+	keys = append(keys, s.key(key))
+	for _, tag := range tags {
+		keys = append(keys, s.tagKey(tag))
+	}
+
+	if err := setWithTags.Run(ctx, s.client, keys, value, ttl.Milliseconds()).Err(); err != nil {
+		return fmt.Errorf("cache: failed to set key %q: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes the given keys.
+func (s *redisStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	prefixed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		prefixed = append(prefixed, s.key(key))
+	}
+
+	if err := s.client.Del(ctx, prefixed...).Err(); err != nil {
+		return fmt.Errorf("cache: failed to delete keys: %w", err)
+	}
+	return nil
+}
+
+// InvalidateTags removes all keys associated with the given tags.
+func (s *redisStore) InvalidateTags(ctx context.Context, tags ...string) error {
+	for _, tag := range tags {
+		tagKey := s.tagKey(tag)
+
+		keys, err := s.client.SMembers(ctx, tagKey).Result()
+		if err != nil {
+			return fmt.Errorf("cache: failed to get keys of tag %q: %w", tag, err)
+		}
+
+		if err := s.client.Del(ctx, append(keys, tagKey)...).Err(); err != nil {
+			return fmt.Errorf("cache: failed to invalidate tag %q: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+// Close does nothing, the Redis client is owned by the caller.
+func (s *redisStore) Close() error {
+	return nil
+}
+
+// key returns the prefixed key.
+func (s *redisStore) key(key string) string {
+	return s.prefix + key
+}
+
+// tagKey returns the key of the tag set.
+func (s *redisStore) tagKey(tag string) string {
+	return s.prefix + "tag:" + tag
+}
@@ -0,0 +1,28 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Store is the interface of a cache backend.
+// It works with raw bytes, values are encoded by the Cache.
+type Store interface {
+	// Get returns the value for the given key.
+	// It returns ErrNotFound if the key does not exist or is expired.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Set stores the value for the given key with the given TTL.
+	// Zero TTL means the value never expires.
+	// The key is added to every given tag, so it can be invalidated by tag.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error
+
+	// Delete removes the given keys.
+	Delete(ctx context.Context, keys ...string) error
+
+	// InvalidateTags removes all keys associated with the given tags.
+	InvalidateTags(ctx context.Context, tags ...string) error
+
+	// Close releases the resources used by the store.
+	Close() error
+}
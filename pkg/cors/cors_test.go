@@ -0,0 +1,165 @@
+package cors_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dmitrymomot/go-app/pkg/cors"
+	"github.com/stretchr/testify/require"
+)
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+func request(t *testing.T, mw func(http.Handler) http.Handler, method, path, origin string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, nil)
+	r.Header.Set("Origin", origin)
+	if method == http.MethodOptions {
+		r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	}
+	w := httptest.NewRecorder()
+	mw(okHandler).ServeHTTP(w, r)
+	return w
+}
+
+func TestPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy cors.Policy
+		err    error
+	}{
+		{"wildcard origin with credentials", cors.Policy{AllowedOrigins: []string{"*"}, AllowCredentials: true}, cors.ErrWildcardWithCredentials},
+		{"wildcard headers with credentials", cors.Policy{AllowedOrigins: []string{"https://a.com"}, AllowedHeaders: []string{"*"}, AllowCredentials: true}, cors.ErrWildcardWithCredentials},
+		{"wildcard exposed headers with credentials", cors.Policy{AllowedOrigins: []string{"https://a.com"}, ExposedHeaders: []string{"*"}, AllowCredentials: true}, cors.ErrWildcardWithCredentials},
+		{"null origin", cors.Policy{AllowedOrigins: []string{"null"}}, cors.ErrUnsafeOrigin},
+		{"wildcard top-level domain", cors.Policy{AllowedOrigins: []string{"https://*.com"}}, cors.ErrUnsafeOrigin},
+		{"invalid regex", cors.Policy{AllowedOrigins: []string{`regex:^https://(.*$`}}, cors.ErrInvalidOrigin},
+		{"origin without scheme", cors.Policy{AllowedOrigins: []string{"example.com"}}, cors.ErrInvalidOrigin},
+		{"origin with path", cors.Policy{AllowedOrigins: []string{"https://example.com/app"}}, cors.ErrInvalidOrigin},
+		{"wildcard in the middle", cors.Policy{AllowedOrigins: []string{"https://app.*.example.com"}}, cors.ErrInvalidOrigin},
+		{"negative max age", cors.Policy{MaxAge: -1}, cors.ErrInvalidMaxAge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorIs(t, tt.policy.Validate(), tt.err)
+		})
+	}
+
+	// Test case: valid policy
+	t.Run("valid policy", func(t *testing.T) {
+		require.NoError(t, cors.Policy{
+			AllowedOrigins:   []string{"https://app.example.com", "https://*.example.com", `regex:^https://pr-\d+\.example\.dev$`},
+			AllowCredentials: true,
+		}.Validate())
+	})
+}
+
+func TestOriginPatterns(t *testing.T) {
+	mw, err := cors.Middleware(cors.Policy{
+		AllowedOrigins: []string{
+			"https://app.example.com", "https://*.example.org", `regex:^https://pr-\d+\.example\.dev$`,
+			`regex:^https://www\.example\.net$|^https://b\.example\.net`, `regex:https://c\.example\.net`,
+		},
+		AllowCredentials: true,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://app.example.com", true},
+		{"https://APP.example.com", true},
+		{"http://app.example.com", false},
+		{"https://app.example.com.evil.com", false},
+		{"https://a.example.org", true},
+		{"https://a.b.example.org", true},
+		{"https://example.org", false},
+		{"https://evilexample.org", false},
+		{"https://evil.com/.example.org", false},
+		{"https://pr-42.example.dev", true},
+		{"https://pr-x.example.dev", false},
+		{"https://b.example.net", true},
+		{"https://b.example.net.evil.com", false},
+		{"https://c.example.net", true},
+		{"https://evil.com/https://c.example.net", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			w := request(t, mw, http.MethodGet, "/", tt.origin)
+			if tt.allowed {
+				require.Equal(t, tt.origin, w.Header().Get("Access-Control-Allow-Origin"))
+				require.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+			} else {
+				require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	// Test case 1: wildcard origin is not reflected
+	t.Run("wildcard origin is not reflected", func(t *testing.T) {
+		mw, err := cors.Middleware(cors.Policy{AllowedOrigins: []string{"*"}})
+		require.NoError(t, err)
+
+		w := request(t, mw, http.MethodGet, "/", "https://any.com")
+		require.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	// Test case 2: exposed headers are sent
+	t.Run("exposed headers are sent", func(t *testing.T) {
+		mw, err := cors.Middleware(cors.Policy{
+			AllowedOrigins: []string{"https://a.com"},
+			ExposedHeaders: []string{"X-Request-Id", "RateLimit-Remaining"},
+		})
+		require.NoError(t, err)
+
+		w := request(t, mw, http.MethodGet, "/", "https://a.com")
+		require.Equal(t, "X-Request-Id, Ratelimit-Remaining", w.Header().Get("Access-Control-Expose-Headers"))
+	})
+
+	// Test case 3: route group policies override the default one
+	t.Run("route group policies override the default one", func(t *testing.T) {
+		mw, err := cors.Middleware(
+			cors.Policy{AllowedOrigins: []string{"https://app.com"}, AllowedMethods: []string{"GET", "POST"}, AllowCredentials: true},
+			cors.WithPathPolicy("/api/public/*", cors.Policy{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET", "POST"}}),
+			cors.WithPathPolicy("/api/public/admin/*", cors.Policy{}),
+			cors.WithPathPolicy("/webhooks", cors.Policy{}),
+		)
+		require.NoError(t, err)
+
+		w := request(t, mw, http.MethodOptions, "/api/public/items", "https://other.com")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+
+		w = request(t, mw, http.MethodGet, "/api/public", "https://other.com")
+		require.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+
+		w = request(t, mw, http.MethodGet, "/api/public/admin/users", "https://app.com")
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+		w = request(t, mw, http.MethodGet, "/webhooks", "https://app.com")
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+		w = request(t, mw, http.MethodGet, "/webhooks/github", "https://app.com")
+		require.Equal(t, "https://app.com", w.Header().Get("Access-Control-Allow-Origin"))
+
+		w = request(t, mw, http.MethodGet, "/api/private", "https://other.com")
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	// Test case 4: unsafe policies fail
+	t.Run("unsafe policies fail", func(t *testing.T) {
+		_, err := cors.Middleware(cors.Policy{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+		require.ErrorIs(t, err, cors.ErrWildcardWithCredentials)
+
+		_, err = cors.Middleware(cors.Policy{}, cors.WithPathPolicy("/api/*", cors.Policy{AllowedOrigins: []string{"null"}}))
+		require.ErrorIs(t, err, cors.ErrUnsafeOrigin)
+
+		_, err = cors.Middleware(cors.Policy{}, cors.WithPathPolicy("api/*", cors.Policy{}))
+		require.ErrorIs(t, err, cors.ErrInvalidPathPattern)
+	})
+}
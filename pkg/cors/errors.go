@@ -0,0 +1,12 @@
+package cors
+
+import "errors"
+
+// Predefined errors.
+var (
+	ErrInvalidOrigin           = errors.New("invalid CORS origin pattern")
+	ErrUnsafeOrigin            = errors.New("unsafe CORS origin pattern")
+	ErrWildcardWithCredentials = errors.New("CORS wildcard is not allowed with credentials")
+	ErrInvalidMaxAge           = errors.New("CORS max age must not be negative")
+	ErrInvalidPathPattern      = errors.New("CORS path pattern must start with /")
+)
@@ -0,0 +1,102 @@
+package cors
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+type (
+	// Option is a function that configures the middleware.
+	Option func(*options)
+
+	options struct {
+		paths []pathPolicy
+	}
+
+	pathPolicy struct {
+		pattern string
+		policy  Policy
+	}
+
+	pathHandler struct {
+		prefix  string
+		exact   bool
+		handler func(http.Handler) http.Handler
+	}
+)
+
+// WithPathPolicy overrides the default policy for the route group.
+// The pattern is either an exact path ("/webhooks") or a path prefix ending with /* ("/api/public/*"),
+// which also matches the prefix itself. The most specific pattern wins.
+func WithPathPolicy(pattern string, p Policy) Option {
+	return func(o *options) {
+		o.paths = append(o.paths, pathPolicy{pattern: pattern, policy: p})
+	}
+}
+
+// Middleware handles CORS requests according to the default policy,
+// or the policy of the matching route group.
+// It returns an error if any policy is invalid or unsafe, so the application
+// fails at startup instead of serving with a broken configuration.
+// Must be used before the router handles OPTIONS requests, i.e. as a top-level middleware.
+func Middleware(p Policy, opts ...Option) (func(http.Handler) http.Handler, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	def, err := p.Handler()
+	if err != nil {
+		return nil, fmt.Errorf("default policy: %w", err)
+	}
+
+	handlers := make([]pathHandler, 0, len(o.paths))
+	for _, pp := range o.paths {
+		if !strings.HasPrefix(pp.pattern, "/") {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPathPattern, pp.pattern)
+		}
+		h, err := pp.policy.Handler()
+		if err != nil {
+			return nil, fmt.Errorf("policy of %s: %w", pp.pattern, err)
+		}
+		prefix := strings.TrimSuffix(pp.pattern, "/*")
+		handlers = append(handlers, pathHandler{
+			prefix:  strings.TrimSuffix(prefix, "/"),
+			exact:   prefix == pp.pattern,
+			handler: h,
+		})
+	}
+	// The longest prefix is the most specific one.
+	sort.SliceStable(handlers, func(i, j int) bool {
+		return len(handlers[i].prefix) > len(handlers[j].prefix)
+	})
+
+	return func(next http.Handler) http.Handler {
+		defHandler := def(next)
+		pathHandlers := make([]http.Handler, len(handlers))
+		for i, h := range handlers {
+			pathHandlers[i] = h.handler(next)
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for i, h := range handlers {
+				if h.match(r.URL.Path) {
+					pathHandlers[i].ServeHTTP(w, r)
+					return
+				}
+			}
+			defHandler.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// match reports whether the path belongs to the route group.
+func (h pathHandler) match(path string) bool {
+	path = strings.TrimSuffix(path, "/")
+	if h.exact {
+		return path == h.prefix
+	}
+	return path == h.prefix || strings.HasPrefix(path, h.prefix+"/")
+}
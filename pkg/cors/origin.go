@@ -0,0 +1,80 @@
+package cors
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// RegexPrefix marks an origin pattern as a regular expression,
+// e.g. `regex:^https://pr-\d+\.example\.com$`.
+// The expression always matches the whole origin, as if it was anchored with ^ and $.
+const RegexPrefix = "regex:"
+
+// originMatcher reports whether the origin is allowed.
+// The origin is lowercased before matching.
+type originMatcher func(origin string) bool
+
+// parseOrigin compiles the origin pattern. Supported patterns:
+//   - "*" matches any origin;
+//   - "https://app.example.com" matches the exact origin;
+//   - "https://*.example.com" matches any subdomain of example.com, but not example.com itself;
+//   - "regex:^https://(www|app)\.example\.com$" matches the regular expression.
+func parseOrigin(pattern string) (originMatcher, error) {
+	pattern = strings.TrimSpace(pattern)
+
+	switch {
+	case pattern == "*":
+		return func(string) bool { return true }, nil
+
+	case strings.HasPrefix(pattern, RegexPrefix):
+		// User's anchors don't cover alternations, e.g. "^a$|^b" matches "b.evil.com"
+		expr := strings.TrimPrefix(pattern, RegexPrefix)
+		re, err := regexp.Compile("(?i)^(?:" + expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %s", ErrInvalidOrigin, pattern, err)
+		}
+		return re.MatchString, nil
+	}
+
+	pattern = strings.ToLower(pattern)
+	if pattern == "null" {
+		// Sandboxed iframes and local files send "null" origin, anyone can forge it.
+		return nil, fmt.Errorf("%w: %q", ErrUnsafeOrigin, pattern)
+	}
+
+	scheme, host, ok := strings.Cut(pattern, "://")
+	if !ok || (scheme != "http" && scheme != "https") || host == "" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidOrigin, pattern)
+	}
+
+	if !strings.Contains(host, "*") {
+		u, err := url.Parse(pattern)
+		if err != nil || u.Host != host {
+			return nil, fmt.Errorf("%w: origin must not have a path: %q", ErrInvalidOrigin, pattern)
+		}
+		return func(origin string) bool { return origin == pattern }, nil
+	}
+
+	// Subdomain wildcard: "*." followed by a domain with at least two labels.
+	domain := strings.TrimPrefix(host, "*.")
+	if domain == host || strings.Contains(domain, "*") {
+		return nil, fmt.Errorf("%w: wildcard is allowed only as the leftmost label: %q", ErrInvalidOrigin, pattern)
+	}
+	if name, _, _ := strings.Cut(domain, ":"); !strings.Contains(strings.Trim(name, "."), ".") {
+		return nil, fmt.Errorf("%w: wildcard matches a top-level domain: %q", ErrUnsafeOrigin, pattern)
+	}
+	if u, err := url.Parse(scheme + "://" + domain); err != nil || u.Host != domain {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidOrigin, pattern)
+	}
+
+	prefix, suffix := scheme+"://", "."+domain
+	return func(origin string) bool {
+		if !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+			return false
+		}
+		sub := origin[len(prefix) : len(origin)-len(suffix)]
+		return sub != "" && !strings.ContainsAny(sub, "/:@?#")
+	}, nil
+}
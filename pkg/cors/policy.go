@@ -0,0 +1,110 @@
+package cors
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	chicors "github.com/go-chi/cors"
+)
+
+// Policy is a CORS policy of a route group.
+type Policy struct {
+	// AllowedOrigins is a list of origin patterns, see parseOrigin for the syntax.
+	// Empty list denies all cross-origin requests.
+	AllowedOrigins []string
+	// AllowedMethods is a list of methods allowed in cross-origin requests.
+	// Default is GET, POST and HEAD.
+	AllowedMethods []string
+	// AllowedHeaders is a list of request headers allowed in cross-origin requests.
+	AllowedHeaders []string
+	// ExposedHeaders is a list of response headers available to the client script.
+	ExposedHeaders []string
+	// AllowCredentials allows requests with cookies and HTTP authentication.
+	// It can't be combined with any wildcard.
+	AllowCredentials bool
+	// MaxAge is how long, in seconds, the result of a preflight request can be cached.
+	MaxAge int
+}
+
+// Validate checks the policy for invalid origin patterns and unsafe combinations.
+func (p Policy) Validate() error {
+	_, err := p.matcher()
+	return err
+}
+
+// Handler returns the middleware applying the policy.
+func (p Policy) Handler() (func(http.Handler) http.Handler, error) {
+	match, err := p.matcher()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := chicors.Options{
+		AllowedMethods:   p.AllowedMethods,
+		AllowedHeaders:   p.AllowedHeaders,
+		ExposedHeaders:   p.ExposedHeaders,
+		AllowCredentials: p.AllowCredentials,
+		MaxAge:           p.MaxAge,
+	}
+	if isWildcard(p.AllowedOrigins) {
+		// Respond with "Access-Control-Allow-Origin: *" instead of reflecting the origin.
+		opts.AllowedOrigins = []string{"*"}
+	} else {
+		opts.AllowOriginFunc = func(_ *http.Request, origin string) bool {
+			return match(strings.ToLower(origin))
+		}
+	}
+
+	return chicors.Handler(opts), nil
+}
+
+// matcher validates the policy and compiles its origin patterns.
+func (p Policy) matcher() (originMatcher, error) {
+	if p.MaxAge < 0 {
+		return nil, ErrInvalidMaxAge
+	}
+	if p.AllowCredentials {
+		if contains(p.AllowedOrigins, "*") {
+			return nil, fmt.Errorf("%w: allowed origins", ErrWildcardWithCredentials)
+		}
+		if contains(p.AllowedHeaders, "*") {
+			return nil, fmt.Errorf("%w: allowed headers", ErrWildcardWithCredentials)
+		}
+		if contains(p.ExposedHeaders, "*") {
+			return nil, fmt.Errorf("%w: exposed headers", ErrWildcardWithCredentials)
+		}
+	}
+
+	matchers := make([]originMatcher, 0, len(p.AllowedOrigins))
+	for _, pattern := range p.AllowedOrigins {
+		m, err := parseOrigin(pattern)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+
+	return func(origin string) bool {
+		for _, m := range matchers {
+			if m(origin) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// isWildcard reports whether the list allows any origin.
+func isWildcard(origins []string) bool {
+	return contains(origins, "*")
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if strings.TrimSpace(v) == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,255 @@
+package csrf
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dmitrymomot/go-app/pkg/session"
+	"github.com/dmitrymomot/go-pkg/response"
+)
+
+type (
+	// Option is a function that configures the middleware.
+	Option func(*options)
+
+	options struct {
+		headerName     string
+		fieldName      string
+		sessionKey     string
+		cookieName     string
+		cookiePath     string
+		cookieDomain   string
+		cookieSecure   bool
+		trustedOrigins map[string]struct{}
+		exposeHeader   bool
+		errorHandler   func(w http.ResponseWriter, r *http.Request, err error)
+	}
+
+	// state is the CSRF token of the request.
+	state struct {
+		*options
+		w     http.ResponseWriter
+		sess  *session.Session
+		token []byte
+	}
+
+	// ctxKey is the context key of the request state.
+	ctxKey struct{}
+)
+
+// Middleware protects cookie-authenticated routes from cross-site request forgery.
+//
+// The token is kept in the session (synchronizer token pattern) if the session
+// middleware is installed before this one, otherwise in a cookie (double-submit pattern).
+// Safe methods and requests with a bearer token are not checked.
+// Unsafe requests must pass the Origin/Referer check and send the token
+// in the X-CSRF-Token header or in the csrf_token form field.
+func Middleware(opts ...Option) func(http.Handler) http.Handler {
+	o := &options{
+		headerName:     "X-CSRF-Token",
+		fieldName:      "csrf_token",
+		sessionKey:     "_csrf_token",
+		cookieName:     "_csrf",
+		cookiePath:     "/",
+		cookieSecure:   true,
+		trustedOrigins: make(map[string]struct{}),
+		errorHandler:   defaultErrorHandler,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			st := &state{
+				options: o,
+				w:       w,
+				sess:    session.FromContext(r.Context()),
+			}
+			st.token = st.load(r)
+			r = r.WithContext(context.WithValue(r.Context(), ctxKey{}, st))
+
+			if isSafeMethod(r.Method) {
+				if o.exposeHeader {
+					// A new token is minted only for an existing session,
+					// so anonymous requests don't create sessions and cookies
+					if st.token != nil || (st.sess != nil && !st.sess.IsNew()) {
+						w.Header().Set(o.headerName, st.masked())
+					}
+					w.Header().Add("Vary", "Cookie")
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if isBearerRequest(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if err := o.checkOrigin(r); err != nil {
+				o.errorHandler(w, r, err)
+				return
+			}
+
+			sent := r.Header.Get(o.headerName)
+			if sent == "" {
+				sent = r.PostFormValue(o.fieldName)
+			}
+			if sent == "" {
+				o.errorHandler(w, r, ErrTokenMissing)
+				return
+			}
+			if st.token == nil || !equal(unmask(sent), st.token) {
+				o.errorHandler(w, r, ErrTokenInvalid)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Token returns the masked CSRF token of the request.
+// The token is generated on the first call and kept in the session or cookie.
+// It returns an empty string if the middleware is not installed.
+func Token(r *http.Request) string {
+	st, ok := r.Context().Value(ctxKey{}).(*state)
+	if !ok {
+		return ""
+	}
+	return st.masked()
+}
+
+// TemplateField returns the hidden input field with the CSRF token
+// to embed into HTML forms:
+//
+//	<form method="POST">{{ .CSRFField }}</form>
+func TemplateField(r *http.Request) template.HTML {
+	st, ok := r.Context().Value(ctxKey{}).(*state)
+	if !ok {
+		return ""
+	}
+	return template.HTML(fmt.Sprintf( // nolint:gosec // values are escaped
+		`<input type="hidden" name="%s" value="%s">`,
+		template.HTMLEscapeString(st.fieldName),
+		template.HTMLEscapeString(st.masked()),
+	))
+}
+
+// load returns the raw token kept in the session or cookie.
+func (st *state) load(r *http.Request) []byte {
+	if st.sess != nil {
+		return decodeToken(st.sess.GetString(st.sessionKey))
+	}
+	if cookie, err := r.Cookie(st.cookieName); err == nil {
+		return decodeToken(cookie.Value)
+	}
+	return nil
+}
+
+// masked returns the masked token, generating and persisting a new one if needed.
+func (st *state) masked() string {
+	if st.token == nil {
+		st.token = generateToken()
+		if st.sess != nil {
+			st.sess.Set(st.sessionKey, encodeToken(st.token))
+		} else {
+			http.SetCookie(st.w, &http.Cookie{
+				Name:     st.cookieName,
+				Value:    encodeToken(st.token),
+				Path:     st.cookiePath,
+				Domain:   st.cookieDomain,
+				Secure:   st.cookieSecure,
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+	}
+	return mask(st.token)
+}
+
+// checkOrigin checks that the request comes from the same or a trusted origin.
+// The Referer header is required for HTTPS requests without the Origin header.
+func (o *options) checkOrigin(r *http.Request) error {
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if !o.isAllowedOrigin(r, origin) {
+			return ErrOriginInvalid
+		}
+		return nil
+	}
+
+	referer := r.Header.Get("Referer")
+	if referer == "" {
+		if isSecureRequest(r) {
+			return ErrNoReferer
+		}
+		return nil
+	}
+
+	u, err := url.Parse(referer)
+	if err != nil || u.Host == "" {
+		return ErrOriginInvalid
+	}
+	if !o.isAllowedOrigin(r, u.Scheme+"://"+u.Host) {
+		return ErrOriginInvalid
+	}
+	return nil
+}
+
+// isAllowedOrigin returns true if the origin is the request scheme and host or a trusted origin.
+func (o *options) isAllowedOrigin(r *http.Request, origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if strings.EqualFold(u.Scheme, requestScheme(r)) && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	_, ok := o.trustedOrigins[strings.ToLower(u.Scheme+"://"+u.Host)]
+	return ok
+}
+
+// isSafeMethod returns true for the methods which must not change the state.
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// isBearerRequest returns true if the request is authenticated with a bearer token,
+// which is never sent by browsers automatically.
+func isBearerRequest(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	return len(auth) > 7 && strings.EqualFold(auth[:7], "bearer ")
+}
+
+// isSecureRequest returns true if the request is made over HTTPS.
+func isSecureRequest(r *http.Request) bool {
+	return r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
+
+// requestScheme returns the scheme of the request as seen by the client.
+func requestScheme(r *http.Request) string {
+	if isSecureRequest(r) {
+		return "https"
+	}
+	return "http"
+}
+
+// defaultErrorHandler responds with 403 Forbidden.
+func defaultErrorHandler(w http.ResponseWriter, _ *http.Request, err error) {
+	resp := response.NewError(
+		http.StatusForbidden,
+		err,
+		"The request was rejected by the CSRF protection.",
+		nil,
+	)
+	response.JSON(w, resp) // nolint:errcheck
+}
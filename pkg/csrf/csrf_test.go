@@ -0,0 +1,199 @@
+package csrf_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/dmitrymomot/go-app/pkg/csrf"
+	"github.com/dmitrymomot/go-app/pkg/session"
+	"github.com/stretchr/testify/require"
+)
+
+func newHandler(opts ...csrf.Option) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/form", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Write([]byte(csrf.Token(r))) // nolint:errcheck
+			return
+		}
+		w.Write([]byte("ok")) // nolint:errcheck
+	})
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok")) // nolint:errcheck
+	})
+	return csrf.Middleware(opts...)(mux)
+}
+
+// getToken requests a token and returns it with the cookies set by the response.
+func getToken(t *testing.T, h http.Handler, cookies []*http.Cookie) (string, []*http.Cookie) {
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/form", nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NotEmpty(t, rec.Body.String())
+	return rec.Body.String(), append(cookies, rec.Result().Cookies()...)
+}
+
+func post(h http.Handler, headers map[string]string, cookies []*http.Cookie, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/form", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddleware_DoubleSubmitCookie(t *testing.T) {
+	h := newHandler(csrf.WithTrustedOrigins("https://app.example.com"))
+	token, cookies := getToken(t, h, nil)
+	require.Len(t, cookies, 1)
+
+	// Test case 1: token in the header
+	t.Run("token in the header", func(t *testing.T) {
+		rec := post(h, map[string]string{"X-CSRF-Token": token}, cookies, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	// Test case 2: token in the form field
+	t.Run("token in the form field", func(t *testing.T) {
+		rec := post(h, nil, cookies, url.Values{"csrf_token": {token}})
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	// Test case 3: masked tokens differ but are all valid
+	t.Run("masked tokens differ but are all valid", func(t *testing.T) {
+		token2, _ := getToken(t, h, cookies)
+		require.NotEqual(t, token, token2)
+		rec := post(h, map[string]string{"X-CSRF-Token": token2}, cookies, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	// Test case 4: missing token
+	t.Run("missing token", func(t *testing.T) {
+		rec := post(h, nil, cookies, nil)
+		require.Equal(t, http.StatusForbidden, rec.Code)
+		require.Contains(t, rec.Body.String(), csrf.ErrTokenMissing.Error())
+	})
+
+	// Test case 5: token without cookie
+	t.Run("token without cookie", func(t *testing.T) {
+		rec := post(h, map[string]string{"X-CSRF-Token": token}, nil, nil)
+		require.Equal(t, http.StatusForbidden, rec.Code)
+		require.Contains(t, rec.Body.String(), csrf.ErrTokenInvalid.Error())
+	})
+
+	// Test case 6: cross-origin request
+	t.Run("cross-origin request", func(t *testing.T) {
+		rec := post(h, map[string]string{"X-CSRF-Token": token, "Origin": "https://evil.com"}, cookies, nil)
+		require.Equal(t, http.StatusForbidden, rec.Code)
+		require.Contains(t, rec.Body.String(), csrf.ErrOriginInvalid.Error())
+
+		rec = post(h, map[string]string{"X-CSRF-Token": token, "Referer": "https://evil.com/page"}, cookies, nil)
+		require.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	// Test case 7: same and trusted origins
+	t.Run("same and trusted origins", func(t *testing.T) {
+		rec := post(h, map[string]string{"X-CSRF-Token": token, "Origin": "http://example.com"}, cookies, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		rec = post(h, map[string]string{"X-CSRF-Token": token, "Origin": "https://app.example.com"}, cookies, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		rec = post(h, map[string]string{"X-CSRF-Token": token, "Origin": "https://example.com", "X-Forwarded-Proto": "https"}, cookies, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	// Test case 8: HTTPS request without referer
+	t.Run("HTTPS request without referer", func(t *testing.T) {
+		rec := post(h, map[string]string{"X-CSRF-Token": token, "X-Forwarded-Proto": "https"}, cookies, nil)
+		require.Equal(t, http.StatusForbidden, rec.Code)
+		require.Contains(t, rec.Body.String(), csrf.ErrNoReferer.Error())
+	})
+
+	// Test case 9: bearer token request is exempt
+	t.Run("bearer token request is exempt", func(t *testing.T) {
+		rec := post(h, map[string]string{"Authorization": "Bearer some.jwt.token"}, nil, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+	// Test case 10: same host with another scheme
+	t.Run("same host with another scheme", func(t *testing.T) {
+		rec := post(h, map[string]string{"X-CSRF-Token": token, "Origin": "https://example.com"}, cookies, nil)
+		require.Equal(t, http.StatusForbidden, rec.Code)
+		require.Contains(t, rec.Body.String(), csrf.ErrOriginInvalid.Error())
+
+		rec = post(h, map[string]string{"X-CSRF-Token": token, "Referer": "http://example.com/page", "X-Forwarded-Proto": "https"}, cookies, nil)
+		require.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func TestMiddleware_SessionToken(t *testing.T) {
+	m, err := session.NewManager(session.NewCookieStore(), []byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+	h := m.Middleware(newHandler(csrf.WithExposeHeader()))
+
+	token, cookies := getToken(t, h, nil)
+	require.Len(t, cookies, 1, "only the session cookie is expected")
+	require.Equal(t, "sid", cookies[0].Name)
+
+	rec := post(h, map[string]string{"X-CSRF-Token": token}, cookies, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = post(h, map[string]string{"X-CSRF-Token": "invalid"}, cookies, nil)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestMiddleware_ExposeHeader(t *testing.T) {
+	m, err := session.NewManager(session.NewCookieStore(), []byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+	h := m.Middleware(newHandler(csrf.WithExposeHeader()))
+
+	get := func(path string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com"+path, nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Test case 1: anonymous request doesn't mint a token
+	t.Run("anonymous request", func(t *testing.T) {
+		rec := get("/page", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, rec.Header().Get("X-CSRF-Token"))
+		require.Empty(t, rec.Result().Cookies())
+	})
+
+	// Test case 2: token minted by Token is exposed on the next requests
+	t.Run("existing token", func(t *testing.T) {
+		_, cookies := getToken(t, h, nil)
+		rec := get("/page", cookies)
+		require.NotEmpty(t, rec.Header().Get("X-CSRF-Token"))
+
+		rec = post(h, map[string]string{"X-CSRF-Token": rec.Header().Get("X-CSRF-Token")}, cookies, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestTemplateField(t *testing.T) {
+	h := csrf.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(csrf.TemplateField(r))) // nolint:errcheck
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Contains(t, rec.Body.String(), `<input type="hidden" name="csrf_token" value="`)
+}
@@ -0,0 +1,11 @@
+package csrf
+
+import "errors"
+
+// Predefined errors.
+var (
+	ErrTokenMissing  = errors.New("csrf_token_missing")
+	ErrTokenInvalid  = errors.New("csrf_token_invalid")
+	ErrOriginInvalid = errors.New("csrf_origin_invalid")
+	ErrNoReferer     = errors.New("csrf_referer_missing")
+)
@@ -0,0 +1,89 @@
+package csrf
+
+import (
+	"net/http"
+	"strings"
+)
+
+// WithHeaderName sets the name of the request header with the token.
+// Default is "X-CSRF-Token".
+func WithHeaderName(name string) Option {
+	return func(o *options) {
+		if name != "" {
+			o.headerName = name
+		}
+	}
+}
+
+// WithFieldName sets the name of the form field with the token.
+// Default is "csrf_token".
+func WithFieldName(name string) Option {
+	return func(o *options) {
+		if name != "" {
+			o.fieldName = name
+		}
+	}
+}
+
+// WithSessionKey sets the key of the token in the session.
+// Default is "_csrf_token".
+func WithSessionKey(key string) Option {
+	return func(o *options) {
+		if key != "" {
+			o.sessionKey = key
+		}
+	}
+}
+
+// WithCookie sets the name, path and domain of the token cookie,
+// which is used if there is no session. Default name is "_csrf".
+func WithCookie(name, path, domain string) Option {
+	return func(o *options) {
+		if name != "" {
+			o.cookieName = name
+		}
+		if path != "" {
+			o.cookiePath = path
+		}
+		o.cookieDomain = domain
+	}
+}
+
+// WithCookieSecure sets the Secure flag of the token cookie. Default is true.
+func WithCookieSecure(secure bool) Option {
+	return func(o *options) {
+		o.cookieSecure = secure
+	}
+}
+
+// WithTrustedOrigins sets the origins allowed to send unsafe requests
+// in addition to the request host, e.g. "https://app.example.com".
+func WithTrustedOrigins(origins ...string) Option {
+	return func(o *options) {
+		for _, origin := range origins {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				o.trustedOrigins[strings.ToLower(strings.TrimSuffix(origin, "/"))] = struct{}{}
+			}
+		}
+	}
+}
+
+// WithExposeHeader enables sending the token in the response header of safe requests,
+// so single-page applications can read it and send it back with unsafe requests.
+// The header is sent if the request already has a token or a stored session,
+// otherwise the token must be requested with Token, e.g. by a bootstrap endpoint.
+func WithExposeHeader() Option {
+	return func(o *options) {
+		o.exposeHeader = true
+	}
+}
+
+// WithErrorHandler sets the handler of rejected requests.
+// Default handler responds with 403 Forbidden.
+func WithErrorHandler(fn func(w http.ResponseWriter, r *http.Request, err error)) Option {
+	return func(o *options) {
+		if fn != nil {
+			o.errorHandler = fn
+		}
+	}
+}
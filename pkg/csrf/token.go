@@ -0,0 +1,63 @@
+package csrf
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+)
+
+// tokenLength is the length of the raw CSRF token in bytes.
+const tokenLength = 32
+
+// generateToken returns a new random token.
+func generateToken() []byte {
+	b := make([]byte, tokenLength)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// mask returns the token XOR-ed with a one-time pad, prefixed with the pad.
+// The masked token is different on every response, which protects it from BREACH attacks.
+func mask(token []byte) string {
+	otp := generateToken()
+	masked := make([]byte, 0, 2*tokenLength)
+	masked = append(masked, otp...)
+	for i := range token {
+		masked = append(masked, token[i]^otp[i])
+	}
+	return base64.RawURLEncoding.EncodeToString(masked)
+}
+
+// unmask returns the raw token from the masked one.
+func unmask(masked string) []byte {
+	b, err := base64.RawURLEncoding.DecodeString(masked)
+	if err != nil || len(b) != 2*tokenLength {
+		return nil
+	}
+	otp, token := b[:tokenLength], b[tokenLength:]
+	for i := range token {
+		token[i] ^= otp[i]
+	}
+	return token
+}
+
+// equal compares the tokens in constant time.
+func equal(a, b []byte) bool {
+	return len(a) == tokenLength && subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// encodeToken encodes the raw token to store it in the session or cookie.
+func encodeToken(token []byte) string {
+	return base64.RawURLEncoding.EncodeToString(token)
+}
+
+// decodeToken decodes the raw token stored in the session or cookie.
+func decodeToken(s string) []byte {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) != tokenLength {
+		return nil
+	}
+	return b
+}
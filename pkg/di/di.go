@@ -0,0 +1,158 @@
+// Package di is a lightweight typed dependency injection container.
+//
+// Constructors are registered per type and resolved lazily, every type is
+// constructed once. Constructors resolve their own dependencies from the container,
+// so the dependency graph doesn't need to be declared up front:
+//
+//	di.Provide(c, func(c *di.Container) (*users.Service, error) {
+//		db, err := di.Resolve[*sql.DB](c)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return users.NewService(db), nil
+//	})
+//
+// The container is meant to be built and resolved at startup, e.g. with ResolveAll,
+// it is not safe for concurrent use.
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type (
+	// Container holds the constructors and the constructed instances.
+	Container struct {
+		providers map[reflect.Type]*provider
+		order     []reflect.Type
+		resolving []reflect.Type
+	}
+
+	// provider constructs and caches the instance of a single type.
+	provider struct {
+		construct func(*Container) (interface{}, error)
+		value     interface{}
+		err       error
+		done      bool
+	}
+)
+
+// New creates a new empty Container.
+func New() *Container {
+	return &Container{providers: make(map[reflect.Type]*provider)}
+}
+
+// Provide registers the constructor of T. Use an interface type
+// to provide an implementation, e.g. Provide[jwt.Interactor].
+func Provide[T any](c *Container, fn func(c *Container) (T, error)) error {
+	if fn == nil {
+		return fmt.Errorf("%w: %s", ErrInvalidConstructor, typeOf[T]())
+	}
+	return c.add(typeOf[T](), &provider{
+		construct: func(c *Container) (interface{}, error) {
+			return fn(c)
+		},
+	})
+}
+
+// Supply registers an already constructed instance of T.
+func Supply[T any](c *Container, v T) error {
+	return c.add(typeOf[T](), &provider{value: v, done: true})
+}
+
+// Resolve returns the instance of T, constructing it and its dependencies if needed.
+// Errors name the chain of types being resolved, e.g.
+// "type is not provided: jwt.Interactor (resolving *users.Handler -> *users.Service -> jwt.Interactor)".
+func Resolve[T any](c *Container) (T, error) {
+	var zero T
+	v, err := c.resolve(typeOf[T]())
+	if err != nil {
+		return zero, err
+	}
+	// A nil interface value can't be asserted, the zero value is returned for it
+	result, _ := v.(T)
+	return result, nil
+}
+
+// MustResolve is like Resolve but panics if the type can't be resolved.
+// It's meant for the types which are known to be provided, e.g. after ResolveAll succeeded.
+func MustResolve[T any](c *Container) T {
+	v, err := Resolve[T](c)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Has reports whether T is provided.
+func Has[T any](c *Container) bool {
+	_, ok := c.providers[typeOf[T]()]
+	return ok
+}
+
+// ResolveAll constructs all provided types in the order of registration,
+// so missing dependencies, cycles and constructor errors are reported at startup.
+func (c *Container) ResolveAll() error {
+	for _, t := range c.order {
+		if _, err := c.resolve(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add registers the provider of the type.
+func (c *Container) add(t reflect.Type, p *provider) error {
+	if _, ok := c.providers[t]; ok {
+		return fmt.Errorf("%w: %s", ErrAlreadyProvided, t)
+	}
+	c.providers[t] = p
+	c.order = append(c.order, t)
+	return nil
+}
+
+// resolve returns the instance of the type, constructing it once.
+func (c *Container) resolve(t reflect.Type) (interface{}, error) {
+	for _, r := range c.resolving {
+		if r == t {
+			return nil, fmt.Errorf("%w: %s", ErrDependencyCycle, c.path(t))
+		}
+	}
+
+	p, ok := c.providers[t]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s (resolving %s)", ErrNotProvided, t, c.path(t))
+	}
+	if p.done {
+		return p.value, p.err
+	}
+
+	c.resolving = append(c.resolving, t)
+	v, err := p.construct(c)
+	c.resolving = c.resolving[:len(c.resolving)-1]
+
+	// Errors of the dependencies already name the chain, they are returned as is
+	if err != nil && !errors.Is(err, ErrNotProvided) && !errors.Is(err, ErrDependencyCycle) && !errors.Is(err, ErrConstructorFailed) {
+		err = &ConstructorError{Path: c.path(t), Err: err}
+	}
+
+	p.value, p.err, p.done = v, err, true
+	return v, err
+}
+
+// path returns the chain of the types being resolved ending with t.
+func (c *Container) path(t reflect.Type) string {
+	names := make([]string, 0, len(c.resolving)+1)
+	for _, r := range c.resolving {
+		names = append(names, r.String())
+	}
+	return strings.Join(append(names, t.String()), " -> ")
+}
+
+// typeOf returns the reflect type of T, including interface types.
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
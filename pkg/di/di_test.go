@@ -0,0 +1,196 @@
+package di_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dmitrymomot/go-app/pkg/di"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	config  struct{ dsn string }
+	db      struct{ cfg *config }
+	service struct{ db *db }
+	handler struct{ svc *service }
+
+	greeter interface{ Greet() string }
+	english struct{}
+
+	cycleA struct{}
+	cycleB struct{}
+)
+
+func (english) Greet() string { return "hello" }
+
+// provideApp provides the chain handler -> service -> db -> config.
+func provideApp(t *testing.T, c *di.Container, calls map[string]int) {
+	t.Helper()
+	require.NoError(t, di.Supply(c, &config{dsn: "postgres://localhost"}))
+	require.NoError(t, di.Provide(c, func(c *di.Container) (*db, error) {
+		calls["db"]++
+		cfg, err := di.Resolve[*config](c)
+		if err != nil {
+			return nil, err
+		}
+		return &db{cfg: cfg}, nil
+	}))
+	require.NoError(t, di.Provide(c, func(c *di.Container) (*service, error) {
+		calls["service"]++
+		d, err := di.Resolve[*db](c)
+		if err != nil {
+			return nil, err
+		}
+		return &service{db: d}, nil
+	}))
+	require.NoError(t, di.Provide(c, func(c *di.Container) (*handler, error) {
+		calls["handler"]++
+		svc, err := di.Resolve[*service](c)
+		if err != nil {
+			return nil, err
+		}
+		return &handler{svc: svc}, nil
+	}))
+}
+
+func TestResolve(t *testing.T) {
+	// Test case 1: dependencies are resolved and constructed once
+	t.Run("resolves dependencies once", func(t *testing.T) {
+		c := di.New()
+		calls := map[string]int{}
+		provideApp(t, c, calls)
+
+		h, err := di.Resolve[*handler](c)
+		require.NoError(t, err)
+		require.Equal(t, "postgres://localhost", h.svc.db.cfg.dsn)
+
+		svc, err := di.Resolve[*service](c)
+		require.NoError(t, err)
+		require.Same(t, h.svc, svc)
+		require.Equal(t, map[string]int{"db": 1, "service": 1, "handler": 1}, calls)
+	})
+
+	// Test case 2: interface types
+	t.Run("interface type", func(t *testing.T) {
+		c := di.New()
+		require.NoError(t, di.Provide(c, func(*di.Container) (greeter, error) { return english{}, nil }))
+
+		g, err := di.Resolve[greeter](c)
+		require.NoError(t, err)
+		require.Equal(t, "hello", g.Greet())
+		require.True(t, di.Has[greeter](c))
+		require.False(t, di.Has[english](c))
+	})
+
+	// Test case 3: missing dependency names the chain
+	t.Run("not provided", func(t *testing.T) {
+		c := di.New()
+		require.NoError(t, di.Provide(c, func(c *di.Container) (*service, error) {
+			d, err := di.Resolve[*db](c)
+			if err != nil {
+				return nil, err
+			}
+			return &service{db: d}, nil
+		}))
+
+		_, err := di.Resolve[*service](c)
+		require.ErrorIs(t, err, di.ErrNotProvided)
+		require.Contains(t, err.Error(), "*di_test.service -> *di_test.db")
+	})
+
+	// Test case 4: dependency cycle
+	t.Run("dependency cycle", func(t *testing.T) {
+		c := di.New()
+		require.NoError(t, di.Provide(c, func(c *di.Container) (*cycleA, error) {
+			_, err := di.Resolve[*cycleB](c)
+			return &cycleA{}, err
+		}))
+		require.NoError(t, di.Provide(c, func(c *di.Container) (*cycleB, error) {
+			_, err := di.Resolve[*cycleA](c)
+			return &cycleB{}, err
+		}))
+
+		_, err := di.Resolve[*cycleA](c)
+		require.ErrorIs(t, err, di.ErrDependencyCycle)
+		require.Contains(t, err.Error(), "*di_test.cycleA -> *di_test.cycleB -> *di_test.cycleA")
+	})
+
+	// Test case 5: constructor error is wrapped with the chain and constructed once
+	t.Run("constructor failed", func(t *testing.T) {
+		c := di.New()
+		calls := map[string]int{}
+		failure := errors.New("connection refused")
+		require.NoError(t, di.Provide(c, func(*di.Container) (*db, error) {
+			calls["db"]++
+			return nil, failure
+		}))
+		require.NoError(t, di.Provide(c, func(c *di.Container) (*service, error) {
+			d, err := di.Resolve[*db](c)
+			if err != nil {
+				return nil, err
+			}
+			return &service{db: d}, nil
+		}))
+
+		_, err := di.Resolve[*service](c)
+		require.ErrorIs(t, err, di.ErrConstructorFailed)
+		require.ErrorIs(t, err, failure)
+		require.Contains(t, err.Error(), "*di_test.service -> *di_test.db: connection refused")
+
+		var cerr *di.ConstructorError
+		require.ErrorAs(t, err, &cerr)
+		require.Equal(t, "*di_test.service -> *di_test.db", cerr.Path)
+
+		_, err = di.Resolve[*db](c)
+		require.ErrorIs(t, err, failure)
+		require.Equal(t, 1, calls["db"])
+	})
+
+	// Test case 6: MustResolve panics if the type can't be resolved
+	t.Run("must resolve", func(t *testing.T) {
+		c := di.New()
+		require.NoError(t, di.Supply(c, &config{dsn: "dsn"}))
+		require.Equal(t, "dsn", di.MustResolve[*config](c).dsn)
+		require.Panics(t, func() { di.MustResolve[*db](c) })
+	})
+}
+
+func TestProvide(t *testing.T) {
+	// Test case 1: duplicate type
+	t.Run("already provided", func(t *testing.T) {
+		c := di.New()
+		require.NoError(t, di.Supply(c, &config{}))
+		require.ErrorIs(t, di.Supply(c, &config{}), di.ErrAlreadyProvided)
+		require.ErrorIs(t, di.Provide(c, func(*di.Container) (*config, error) { return &config{}, nil }), di.ErrAlreadyProvided)
+	})
+
+	// Test case 2: nil constructor
+	t.Run("invalid constructor", func(t *testing.T) {
+		require.ErrorIs(t, di.Provide[*config](di.New(), nil), di.ErrInvalidConstructor)
+	})
+}
+
+func TestContainer_ResolveAll(t *testing.T) {
+	// Test case 1: all types are constructed
+	t.Run("constructs all types", func(t *testing.T) {
+		c := di.New()
+		calls := map[string]int{}
+		provideApp(t, c, calls)
+
+		require.NoError(t, c.ResolveAll())
+		require.Equal(t, map[string]int{"db": 1, "service": 1, "handler": 1}, calls)
+	})
+
+	// Test case 2: the first error is reported
+	t.Run("reports errors at startup", func(t *testing.T) {
+		c := di.New()
+		require.NoError(t, di.Provide(c, func(c *di.Container) (*handler, error) {
+			svc, err := di.Resolve[*service](c)
+			return &handler{svc: svc}, err
+		}))
+
+		err := c.ResolveAll()
+		require.ErrorIs(t, err, di.ErrNotProvided)
+		require.Contains(t, err.Error(), "*di_test.handler -> *di_test.service")
+	})
+}
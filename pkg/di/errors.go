@@ -0,0 +1,38 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Predefined errors.
+var (
+	ErrInvalidConstructor = errors.New("constructor must not be nil")
+	ErrAlreadyProvided    = errors.New("type is already provided")
+	ErrNotProvided        = errors.New("type is not provided")
+	ErrDependencyCycle    = errors.New("dependency cycle")
+	ErrConstructorFailed  = errors.New("constructor failed")
+)
+
+// ConstructorError is returned when a constructor fails.
+// It matches ErrConstructorFailed and unwraps to the error of the constructor.
+type ConstructorError struct {
+	// Path is the chain of the types being resolved, ending with the type of the constructor.
+	Path string
+	Err  error
+}
+
+// Error implements the error interface.
+func (e *ConstructorError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", ErrConstructorFailed, e.Path, e.Err)
+}
+
+// Unwrap returns the error of the constructor.
+func (e *ConstructorError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is ErrConstructorFailed.
+func (e *ConstructorError) Is(target error) bool {
+	return target == ErrConstructorFailed
+}
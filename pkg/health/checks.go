@@ -0,0 +1,59 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// DB checks the database connection.
+func DB(db *sql.DB) CheckFunc {
+	return db.PingContext
+}
+
+// Redis checks the Redis connection.
+func Redis(client redis.UniversalClient) CheckFunc {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	}
+}
+
+// Migrations checks that all migrations from the source are applied to the postgres database.
+// Migrations applied by a newer release are ignored, so old replicas stay ready during a rolling deploy.
+// Migrations are applied once per deployment, so cache the result for a while, e.g.:
+//
+//	registry.Register("migrations", health.Migrations(db, source, "migrations"),
+//		health.WithCheckCacheTTL(time.Minute))
+func Migrations(db *sql.DB, source migrate.MigrationSource, table string) CheckFunc {
+	set := migrate.MigrationSet{TableName: table, IgnoreUnknown: true}
+	return func(ctx context.Context) error {
+		// sql-migrate doesn't support contexts, the check is abandoned on timeout.
+		planned, _, err := set.PlanMigration(db, "postgres", source, migrate.Up, 0)
+		if err != nil {
+			return fmt.Errorf("failed to plan migrations: %w", err)
+		}
+		if len(planned) > 0 {
+			return fmt.Errorf("%w: %d pending", ErrPendingMigrations, len(planned))
+		}
+		return nil
+	}
+}
+
+// QueueLag checks that the age of the oldest pending task returned by lag doesn't exceed max,
+// e.g. asynq.Inspector.GetQueueInfo(queue).Latency.
+func QueueLag(lag func(ctx context.Context) (time.Duration, error), max time.Duration) CheckFunc {
+	return func(ctx context.Context) error {
+		d, err := lag(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get queue lag: %w", err)
+		}
+		if d > max {
+			return fmt.Errorf("%w: %s > %s", ErrQueueLag, d.Round(time.Millisecond), max)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,13 @@
+package health
+
+import "errors"
+
+// Predefined errors.
+var (
+	ErrInvalidCheckName   = errors.New("check name must not be empty")
+	ErrInvalidCheckFunc   = errors.New("check function must not be nil")
+	ErrCheckAlreadyExists = errors.New("check with the same name is already registered")
+	ErrShuttingDown       = errors.New("shutting down")
+	ErrPendingMigrations  = errors.New("database migrations are not applied")
+	ErrQueueLag           = errors.New("queue lag exceeds the threshold")
+)
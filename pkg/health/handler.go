@@ -0,0 +1,41 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Handler returns the HTTP handler of the probe.
+// It responds with 200 OK or 503 Service Unavailable and a plain text status.
+// Add the "verbose" query parameter to get the JSON report with results of every check.
+func (r *Registry) Handler(probe Probe) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		report := r.Check(req.Context(), probe)
+
+		code := http.StatusOK
+		if report.Status != StatusOK {
+			code = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		if _, verbose := req.URL.Query()["verbose"]; verbose {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(code)
+			_ = json.NewEncoder(w).Encode(report)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(report.Status))
+	}
+}
+
+// Routes registers the /livez, /readyz and /startupz endpoints.
+func (r *Registry) Routes(router chi.Router) {
+	for _, p := range []Probe{Liveness, Readiness, Startup} {
+		router.Get("/"+string(p), r.Handler(p))
+	}
+}
@@ -0,0 +1,228 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Probe is a kind of health check endpoint.
+type Probe string
+
+// Predefined probes.
+const (
+	// Liveness fails if the process is broken and must be restarted.
+	// Keep it free of dependency checks: a database outage must not restart every instance.
+	Liveness Probe = "livez"
+	// Readiness fails if the instance can't serve traffic and must be removed from the load balancer.
+	// It also fails as soon as shutdown begins.
+	Readiness Probe = "readyz"
+	// Startup fails until the instance has started.
+	// Once it passes, it keeps passing without running the checks again.
+	Startup Probe = "startupz"
+)
+
+// Check statuses.
+const (
+	StatusOK     = "ok"
+	StatusFailed = "failed"
+)
+
+type (
+	// CheckFunc checks a dependency and returns an error if it's unhealthy.
+	// The context is canceled when the check timeout is exceeded.
+	CheckFunc func(ctx context.Context) error
+
+	// Result is the result of a single check.
+	Result struct {
+		Status    string    `json:"status"`
+		Error     string    `json:"error,omitempty"`
+		Duration  string    `json:"duration"`
+		CheckedAt time.Time `json:"checked_at"`
+	}
+
+	// Report is the result of a probe.
+	Report struct {
+		Status string            `json:"status"`
+		Error  string            `json:"error,omitempty"`
+		Checks map[string]Result `json:"checks"`
+	}
+
+	// Registry is a registry of named checks.
+	Registry struct {
+		mu      sync.RWMutex
+		checks  map[string]*check
+		timeout time.Duration
+		ttl     time.Duration
+
+		started      atomic.Bool
+		shuttingDown atomic.Bool
+	}
+
+	check struct {
+		name    string
+		fn      CheckFunc
+		probes  map[Probe]bool
+		timeout time.Duration
+		ttl     time.Duration
+
+		mu     sync.Mutex
+		result Result
+		err    error
+	}
+)
+
+// New creates a new registry of checks.
+func New(opts ...Option) *Registry {
+	r := &Registry{
+		checks:  make(map[string]*check),
+		timeout: 2 * time.Second,
+		ttl:     time.Second,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Register adds the named check.
+// By default the check is used by the readiness and startup probes,
+// with the registry timeout and cache TTL.
+func (r *Registry) Register(name string, fn CheckFunc, opts ...CheckOption) error {
+	if name == "" {
+		return ErrInvalidCheckName
+	}
+	if fn == nil {
+		return ErrInvalidCheckFunc
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.checks[name]; ok {
+		return fmt.Errorf("%w: %s", ErrCheckAlreadyExists, name)
+	}
+
+	c := &check{
+		name:    name,
+		fn:      fn,
+		probes:  map[Probe]bool{Readiness: true, Startup: true},
+		timeout: r.timeout,
+		ttl:     r.ttl,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	r.checks[name] = c
+
+	return nil
+}
+
+// Shutdown makes the readiness probe fail, so load balancers stop routing traffic
+// to the instance while in-flight requests are completed.
+func (r *Registry) Shutdown() {
+	r.shuttingDown.Store(true)
+}
+
+// Check runs all checks of the probe concurrently and returns the report.
+// Results are cached for the check TTL, so frequent probes don't overload dependencies.
+func (r *Registry) Check(ctx context.Context, probe Probe) Report {
+	report := Report{Status: StatusOK, Checks: make(map[string]Result)}
+
+	if probe == Readiness && r.shuttingDown.Load() {
+		report.Status, report.Error = StatusFailed, ErrShuttingDown.Error()
+		return report
+	}
+	if probe == Startup && r.started.Load() {
+		return report
+	}
+
+	checks := r.probeChecks(probe)
+	results := make([]Result, len(checks))
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			results[i] = c.run(ctx)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFailed
+		}
+	}
+
+	if probe == Startup && report.Status == StatusOK {
+		r.started.Store(true)
+	}
+
+	return report
+}
+
+// probeChecks returns the checks of the probe sorted by name.
+func (r *Registry) probeChecks(probe Probe) []*check {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	checks := make([]*check, 0, len(r.checks))
+	for _, c := range r.checks {
+		if c.probes[probe] {
+			checks = append(checks, c)
+		}
+	}
+	sort.Slice(checks, func(i, j int) bool { return checks[i].name < checks[j].name })
+
+	return checks
+}
+
+// run returns the cached result or runs the check with the timeout.
+func (c *check) run(ctx context.Context) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.result.CheckedAt.IsZero() && time.Since(c.result.CheckedAt) < c.ttl {
+		return c.result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.call(ctx)
+
+	c.result = Result{Status: StatusOK, Duration: time.Since(start).String(), CheckedAt: start}
+	if err != nil {
+		c.result.Status, c.result.Error = StatusFailed, err.Error()
+	}
+
+	return c.result
+}
+
+// call runs the check function, a panic or exceeded timeout are reported as errors.
+// A check ignoring the context is abandoned after the timeout.
+func (c *check) call(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				done <- fmt.Errorf("check panicked: %v", rec)
+			}
+		}()
+		done <- c.fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("check timed out after %s: %w", c.timeout, ctx.Err())
+	}
+}
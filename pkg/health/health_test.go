@@ -0,0 +1,202 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dmitrymomot/go-app/internal/repository/repotest"
+	"github.com/dmitrymomot/go-app/pkg/health"
+	"github.com/go-chi/chi/v5"
+	migrate "github.com/rubenv/sql-migrate"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	ok := func(context.Context) error { return nil }
+
+	// Test case 1: checks are used by their probes only
+	t.Run("checks are used by their probes only", func(t *testing.T) {
+		r := health.New()
+		require.NoError(t, r.Register("db", ok))
+		require.NoError(t, r.Register("deadlock", ok, health.ForProbes(health.Liveness)))
+
+		require.Len(t, r.Check(ctx, health.Readiness).Checks, 1)
+		require.Contains(t, r.Check(ctx, health.Readiness).Checks, "db")
+		require.Contains(t, r.Check(ctx, health.Startup).Checks, "db")
+		require.Contains(t, r.Check(ctx, health.Liveness).Checks, "deadlock")
+		require.Len(t, r.Check(ctx, health.Liveness).Checks, 1)
+	})
+
+	// Test case 2: invalid registrations are rejected
+	t.Run("invalid registrations are rejected", func(t *testing.T) {
+		r := health.New()
+		require.ErrorIs(t, r.Register("", ok), health.ErrInvalidCheckName)
+		require.ErrorIs(t, r.Register("db", nil), health.ErrInvalidCheckFunc)
+		require.NoError(t, r.Register("db", ok))
+		require.ErrorIs(t, r.Register("db", ok), health.ErrCheckAlreadyExists)
+	})
+
+	// Test case 3: failed check fails the probe
+	t.Run("failed check fails the probe", func(t *testing.T) {
+		r := health.New()
+		require.NoError(t, r.Register("db", ok))
+		require.NoError(t, r.Register("redis", func(context.Context) error { return errors.New("connection refused") }))
+
+		report := r.Check(ctx, health.Readiness)
+		require.Equal(t, health.StatusFailed, report.Status)
+		require.Equal(t, health.StatusOK, report.Checks["db"].Status)
+		require.Equal(t, "connection refused", report.Checks["redis"].Error)
+	})
+
+	// Test case 4: results are cached
+	t.Run("results are cached", func(t *testing.T) {
+		var calls int32
+		r := health.New(health.WithCacheTTL(time.Hour))
+		require.NoError(t, r.Register("db", func(context.Context) error {
+			atomic.AddInt32(&calls, 1)
+			return nil
+		}))
+		require.NoError(t, r.Register("fresh", func(context.Context) error {
+			atomic.AddInt32(&calls, 10)
+			return nil
+		}, health.WithCheckCacheTTL(-1)))
+
+		r.Check(ctx, health.Readiness)
+		r.Check(ctx, health.Readiness)
+		require.EqualValues(t, 21, atomic.LoadInt32(&calls))
+	})
+
+	// Test case 5: slow check times out
+	t.Run("slow check times out", func(t *testing.T) {
+		r := health.New(health.WithTimeout(time.Hour))
+		require.NoError(t, r.Register("slow", func(context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}, health.WithCheckTimeout(20*time.Millisecond)))
+
+		start := time.Now()
+		report := r.Check(ctx, health.Readiness)
+		require.Less(t, time.Since(start), 500*time.Millisecond)
+		require.Equal(t, health.StatusFailed, report.Status)
+		require.Contains(t, report.Checks["slow"].Error, "timed out")
+	})
+
+	// Test case 6: panicking check fails
+	t.Run("panicking check fails", func(t *testing.T) {
+		r := health.New()
+		require.NoError(t, r.Register("panic", func(context.Context) error { panic("boom") }))
+		require.Contains(t, r.Check(ctx, health.Readiness).Checks["panic"].Error, "boom")
+	})
+
+	// Test case 7: readiness fails after shutdown
+	t.Run("readiness fails after shutdown", func(t *testing.T) {
+		r := health.New()
+		require.NoError(t, r.Register("db", ok))
+		require.Equal(t, health.StatusOK, r.Check(ctx, health.Readiness).Status)
+
+		r.Shutdown()
+		report := r.Check(ctx, health.Readiness)
+		require.Equal(t, health.StatusFailed, report.Status)
+		require.Equal(t, health.ErrShuttingDown.Error(), report.Error)
+		require.Equal(t, health.StatusOK, r.Check(ctx, health.Liveness).Status)
+	})
+
+	// Test case 8: startup keeps passing once passed
+	t.Run("startup keeps passing once passed", func(t *testing.T) {
+		var healthy atomic.Bool
+		r := health.New(health.WithCacheTTL(-1))
+		require.NoError(t, r.Register("migrations", func(context.Context) error {
+			if !healthy.Load() {
+				return health.ErrPendingMigrations
+			}
+			return nil
+		}))
+
+		require.Equal(t, health.StatusFailed, r.Check(ctx, health.Startup).Status)
+		healthy.Store(true)
+		require.Equal(t, health.StatusOK, r.Check(ctx, health.Startup).Status)
+		healthy.Store(false)
+		require.Equal(t, health.StatusOK, r.Check(ctx, health.Startup).Status)
+		require.Equal(t, health.StatusFailed, r.Check(ctx, health.Readiness).Status)
+	})
+}
+
+func TestMigrations(t *testing.T) {
+	db := repotest.DB(t)
+	ctx := context.Background()
+
+	migration := func(id string) *migrate.Migration {
+		return &migrate.Migration{
+			Id:   id,
+			Up:   []string{"SELECT 1"},
+			Down: []string{"SELECT 1"},
+		}
+	}
+
+	// Apply two migrations, as a newer release would do
+	set := migrate.MigrationSet{TableName: "health_migrations"}
+	_, err := set.Exec(db, "postgres", &migrate.MemoryMigrationSource{
+		Migrations: []*migrate.Migration{migration("1-first.sql"), migration("2-newer.sql")},
+	}, migrate.Up)
+	require.NoError(t, err)
+
+	// Test case 1: migrations unknown to the source are ignored
+	t.Run("unknown migrations are ignored", func(t *testing.T) {
+		check := health.Migrations(db, &migrate.MemoryMigrationSource{
+			Migrations: []*migrate.Migration{migration("1-first.sql")},
+		}, "health_migrations")
+		require.NoError(t, check(ctx))
+	})
+
+	// Test case 2: migrations of the source which are not applied are pending
+	t.Run("pending migrations", func(t *testing.T) {
+		check := health.Migrations(db, &migrate.MemoryMigrationSource{
+			Migrations: []*migrate.Migration{migration("1-first.sql"), migration("3-pending.sql")},
+		}, "health_migrations")
+		require.ErrorIs(t, check(ctx), health.ErrPendingMigrations)
+	})
+}
+
+func TestHandler(t *testing.T) {
+	r := health.New()
+	require.NoError(t, r.Register("db", func(context.Context) error { return nil }))
+	require.NoError(t, r.Register("queue", health.QueueLag(func(context.Context) (time.Duration, error) {
+		return time.Minute, nil
+	}, 10*time.Second)))
+
+	router := chi.NewRouter()
+	r.Routes(router)
+
+	// Test case 1: plain text status
+	t.Run("plain text status", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/livez", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "ok", w.Body.String())
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		require.Equal(t, "failed", w.Body.String())
+	})
+
+	// Test case 2: verbose JSON report
+	t.Run("verbose JSON report", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/startupz?verbose", nil))
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+		var report health.Report
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		require.Equal(t, health.StatusOK, report.Checks["db"].Status)
+		require.Equal(t, health.StatusFailed, report.Checks["queue"].Status)
+		require.Contains(t, report.Checks["queue"].Error, health.ErrQueueLag.Error())
+	})
+}
@@ -0,0 +1,55 @@
+package health
+
+import "time"
+
+type (
+	// Option is a function that configures the registry.
+	Option func(*Registry)
+
+	// CheckOption is a function that configures a check.
+	CheckOption func(*check)
+)
+
+// WithTimeout sets the default timeout of checks.
+// Default is 2 seconds.
+func WithTimeout(d time.Duration) Option {
+	return func(r *Registry) {
+		if d > 0 {
+			r.timeout = d
+		}
+	}
+}
+
+// WithCacheTTL sets the default time to cache check results.
+// Default is 1 second, use a negative value to disable caching.
+func WithCacheTTL(d time.Duration) Option {
+	return func(r *Registry) {
+		r.ttl = d
+	}
+}
+
+// ForProbes sets the probes using the check, replacing the default readiness and startup probes.
+func ForProbes(probes ...Probe) CheckOption {
+	return func(c *check) {
+		c.probes = make(map[Probe]bool, len(probes))
+		for _, p := range probes {
+			c.probes[p] = true
+		}
+	}
+}
+
+// WithCheckTimeout overrides the registry timeout for the check.
+func WithCheckTimeout(d time.Duration) CheckOption {
+	return func(c *check) {
+		if d > 0 {
+			c.timeout = d
+		}
+	}
+}
+
+// WithCheckCacheTTL overrides the registry cache TTL for the check.
+func WithCheckCacheTTL(d time.Duration) CheckOption {
+	return func(c *check) {
+		c.ttl = d
+	}
+}
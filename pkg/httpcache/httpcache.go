@@ -0,0 +1,354 @@
+package httpcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmitrymomot/go-app/pkg/cache"
+)
+
+type (
+	// Option is a function that configures the middleware.
+	Option func(*options)
+
+	options struct {
+		store        cache.Store
+		prefix       string
+		errorHandler func(error)
+	}
+
+	// state is shared between the global middleware and the route policy middleware.
+	state struct {
+		*options
+		policy    *Policy
+		cacheable bool
+		key       string
+		hit       bool
+	}
+
+	// entry is a response stored in the cache backend.
+	entry struct {
+		Status   int         `json:"status"`
+		Header   http.Header `json:"header"`
+		Body     []byte      `json:"body"`
+		StoredAt time.Time   `json:"stored_at"`
+	}
+
+	// ctxKey is the context key of the middleware state.
+	ctxKey struct{}
+)
+
+// Response headers which belong to a single request, e.g. a CSRF token or the CORS and rate limit
+// headers computed for the caller. They are never stored nor replayed from the cache backend.
+var perRequestHeaders = map[string]bool{
+	"Set-Cookie":   true,
+	"X-Csrf-Token": true,
+	"X-Request-Id": true,
+	"Retry-After":  true,
+	"Age":          true,
+	"Date":         true,
+}
+
+// Prefixes of per-request response headers.
+var perRequestHeaderPrefixes = []string{"Access-Control-", "Ratelimit-", "X-Ratelimit-"}
+
+// Headers set for responses of routes without a cache policy.
+// It's the same set of headers as in chi's middleware.NoCache.
+var noCacheHeaders = map[string]string{
+	"Expires":         time.Unix(0, 0).UTC().Format(http.TimeFormat),
+	"Cache-Control":   "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+	"Pragma":          "no-cache",
+	"X-Accel-Expires": "0",
+}
+
+// WithStore sets the cache backend used to store full responses
+// of routes with Policy.Store enabled.
+func WithStore(store cache.Store) Option {
+	return func(o *options) {
+		o.store = store
+	}
+}
+
+// WithKeyPrefix sets the prefix of the keys of stored responses.
+// Default is "httpcache:".
+func WithKeyPrefix(prefix string) Option {
+	return func(o *options) {
+		o.prefix = prefix
+	}
+}
+
+// WithErrorHandler sets the function called on cache backend errors.
+// The errors never break the response.
+func WithErrorHandler(fn func(error)) Option {
+	return func(o *options) {
+		if fn != nil {
+			o.errorHandler = fn
+		}
+	}
+}
+
+// Middleware is a replacement of chi's middleware.NoCache.
+// Responses of routes without a policy get the same no-cache headers,
+// routes opted in with Use get the Cache-Control header of their policy,
+// an ETag and 304 Not Modified responses to conditional requests.
+func Middleware(opts ...Option) func(http.Handler) http.Handler {
+	o := &options{
+		prefix:       "httpcache:",
+		errorHandler: func(error) {},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			st := &state{
+				options:   o,
+				cacheable: r.Method == http.MethodGet || r.Method == http.MethodHead,
+			}
+			rw := &responseWriter{ResponseWriter: w, state: st}
+
+			next.ServeHTTP(rw, r.WithContext(context.WithValue(r.Context(), ctxKey{}, st)))
+
+			if !rw.wroteHeader {
+				rw.WriteHeader(http.StatusOK)
+			}
+			if rw.buffered {
+				st.finish(rw, r)
+			}
+		})
+	}
+}
+
+// Use opts the route in to the given cache policy.
+// It must be used on a router with Middleware installed, e.g.:
+//
+//	r.With(httpcache.Use(httpcache.Policy{MaxAge: time.Minute})).Get("/articles", handler)
+func Use(p Policy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			st, ok := r.Context().Value(ctxKey{}).(*state)
+			if !ok {
+				// The middleware is not installed, so only the header can be set.
+				w.Header().Set("Cache-Control", p.CacheControl())
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			st.policy = &p
+			// Requests with credentials bypass the shared cache, their responses may be personalized
+			if !st.cacheable || st.store == nil || p.storeTTL() <= 0 ||
+				r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			st.key = st.prefix + storeKey(r, p.Vary)
+			if !strings.Contains(r.Header.Get("Cache-Control"), "no-cache") {
+				if e, ok := st.load(r.Context()); ok {
+					st.hit = true
+					for k, v := range e.Header {
+						if !isPerRequestHeader(k) {
+							w.Header()[k] = v
+						}
+					}
+					w.Header().Set("Age", seconds(time.Since(e.StoredAt)))
+					w.WriteHeader(e.Status)
+					w.Write(e.Body) // nolint:errcheck
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// finish writes the buffered response of a route with a policy.
+func (st *state) finish(rw *responseWriter, r *http.Request) {
+	h := rw.Header()
+	p := st.policy
+	body := rw.buf.Bytes()
+
+	if rw.status != http.StatusOK || p.NoStore {
+		if p.NoStore {
+			h.Set("Cache-Control", p.CacheControl())
+		} else {
+			setNoCacheHeaders(h)
+		}
+		rw.ResponseWriter.WriteHeader(rw.status)
+		rw.ResponseWriter.Write(body) // nolint:errcheck
+		return
+	}
+
+	h.Set("Cache-Control", p.CacheControl())
+	addVary(h, p.Vary...)
+	if h.Get("ETag") == "" {
+		h.Set("ETag", etag(body, p.WeakETag))
+	}
+
+	// Responses varying on anything but the request headers in the key are not stored
+	if st.key != "" && !st.hit && h.Get("Set-Cookie") == "" && !hasVary(h, "*") {
+		st.save(r.Context(), h, body, p.storeTTL())
+	}
+
+	if notModified(r, h) {
+		h.Del("Content-Type")
+		h.Del("Content-Length")
+		h.Del("Content-Encoding")
+		h.Del("Last-Modified")
+		rw.ResponseWriter.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	h.Set("Content-Length", strconv.Itoa(len(body)))
+	rw.ResponseWriter.WriteHeader(http.StatusOK)
+	rw.ResponseWriter.Write(body) // nolint:errcheck
+}
+
+// load returns the stored response.
+func (st *state) load(ctx context.Context) (*entry, bool) {
+	data, err := st.store.Get(ctx, st.key)
+	if err != nil {
+		if !errors.Is(err, cache.ErrNotFound) {
+			st.errorHandler(err)
+		}
+		return nil, false
+	}
+
+	e := &entry{}
+	if err := json.Unmarshal(data, e); err != nil {
+		st.errorHandler(err)
+		return nil, false
+	}
+
+	return e, true
+}
+
+// save stores the response in the cache backend.
+func (st *state) save(ctx context.Context, h http.Header, body []byte, ttl time.Duration) {
+	header := h.Clone()
+	for k := range header {
+		if isPerRequestHeader(k) {
+			header.Del(k)
+		}
+	}
+
+	data, err := json.Marshal(entry{
+		Status:   http.StatusOK,
+		Header:   header,
+		Body:     body,
+		StoredAt: time.Now(),
+	})
+	if err != nil {
+		st.errorHandler(err)
+		return
+	}
+
+	if err := st.store.Set(ctx, st.key, data, ttl); err != nil {
+		st.errorHandler(err)
+	}
+}
+
+// keyHeaders are the request headers which are always a part of the key, because
+// other middlewares add them to Vary: Origin by CORS, Cookie by session and CSRF.
+// Requests with cookies bypass the store, so the Cookie value is always empty in the key.
+var keyHeaders = []string{"Origin", "Cookie"}
+
+// storeKey returns the key of the stored response built from the URL
+// and the values of the Vary request headers.
+func storeKey(r *http.Request, vary []string) string {
+	h := sha256.New()
+	h.Write([]byte(r.Host + r.URL.RequestURI())) // nolint:errcheck
+	for _, name := range append(keyHeaders, vary...) {
+		h.Write([]byte("\n" + http.CanonicalHeaderKey(name) + ":" + r.Header.Get(name))) // nolint:errcheck
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// isPerRequestHeader reports whether the response header belongs to a single request.
+func isPerRequestHeader(name string) bool {
+	name = http.CanonicalHeaderKey(name)
+	if perRequestHeaders[name] {
+		return true
+	}
+	for _, prefix := range perRequestHeaderPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// addVary adds the names to the Vary header, keeping the values set by other middlewares.
+func addVary(h http.Header, names ...string) {
+	for _, name := range names {
+		if !hasVary(h, name) {
+			h.Add("Vary", http.CanonicalHeaderKey(name))
+		}
+	}
+}
+
+// hasVary reports whether the Vary header contains the name.
+func hasVary(h http.Header, name string) bool {
+	for _, v := range h.Values("Vary") {
+		for _, field := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// etag returns the ETag of the response body.
+func etag(body []byte, weak bool) string {
+	sum := sha256.Sum256(body)
+	tag := `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+	if weak {
+		return "W/" + tag
+	}
+	return tag
+}
+
+// notModified checks the conditional request headers against the response.
+// If-None-Match takes precedence over If-Modified-Since, see RFC 7232.
+func notModified(r *http.Request, h http.Header) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		current := strings.TrimPrefix(h.Get("ETag"), "W/")
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == current {
+				return true
+			}
+		}
+		return false
+	}
+
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	lm, err := http.ParseTime(h.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+	return !lm.Truncate(time.Second).After(ims)
+}
+
+// setNoCacheHeaders sets the no-cache headers which are not set by the handler.
+func setNoCacheHeaders(h http.Header) {
+	for k, v := range noCacheHeaders {
+		if h.Get(k) == "" {
+			h.Set(k, v)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package httpcache_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dmitrymomot/go-app/pkg/cache"
+	"github.com/dmitrymomot/go-app/pkg/httpcache"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func newRouter(calls *int32) *chi.Mux {
+	r := chi.NewRouter()
+	r.Use(httpcache.Middleware(httpcache.WithStore(cache.NewMemoryStore(0))))
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("hello")) // nolint:errcheck
+	}
+
+	r.Get("/no-policy", handler)
+	r.With(httpcache.Use(httpcache.Policy{MaxAge: time.Minute})).Get("/cached", handler)
+	r.With(httpcache.Use(httpcache.Policy{MaxAge: time.Minute, WeakETag: true})).Get("/weak", handler)
+	r.With(httpcache.Use(httpcache.Policy{MaxAge: time.Minute, Store: true, Vary: []string{"Accept-Language"}})).Get("/stored", handler)
+	r.With(httpcache.Use(httpcache.Policy{MaxAge: time.Minute})).Get("/last-modified", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Last-Modified", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat))
+		handler(w, r)
+	})
+
+	return r
+}
+
+func do(r http.Handler, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddleware(t *testing.T) {
+	// Test case 1: route without policy gets no-cache headers
+	t.Run("route without policy gets no-cache headers", func(t *testing.T) {
+		var calls int32
+		rec := do(newRouter(&calls), "/no-policy", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "hello", rec.Body.String())
+		require.Contains(t, rec.Header().Get("Cache-Control"), "no-store")
+		require.Equal(t, "no-cache", rec.Header().Get("Pragma"))
+		require.Empty(t, rec.Header().Get("ETag"))
+	})
+
+	// Test case 2: route with policy gets cache headers and etag
+	t.Run("route with policy gets cache headers and etag", func(t *testing.T) {
+		var calls int32
+		r := newRouter(&calls)
+
+		rec := do(r, "/cached", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "hello", rec.Body.String())
+		require.Equal(t, "public, max-age=60", rec.Header().Get("Cache-Control"))
+		require.Empty(t, rec.Header().Get("Pragma"))
+
+		etag := rec.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+		require.NotContains(t, etag, "W/")
+
+		rec = do(r, "/cached", map[string]string{"If-None-Match": etag})
+		require.Equal(t, http.StatusNotModified, rec.Code)
+		require.Empty(t, rec.Body.String())
+		require.Equal(t, etag, rec.Header().Get("ETag"))
+
+		rec = do(r, "/cached", map[string]string{"If-None-Match": `"other"`})
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	// Test case 3: weak etag matches with weak comparison
+	t.Run("weak etag matches with weak comparison", func(t *testing.T) {
+		var calls int32
+		r := newRouter(&calls)
+
+		rec := do(r, "/weak", nil)
+		etag := rec.Header().Get("ETag")
+		require.Contains(t, etag, "W/")
+
+		rec = do(r, "/weak", map[string]string{"If-None-Match": etag[2:]})
+		require.Equal(t, http.StatusNotModified, rec.Code)
+	})
+
+	// Test case 4: if-modified-since is honored
+	t.Run("if-modified-since is honored", func(t *testing.T) {
+		var calls int32
+		r := newRouter(&calls)
+
+		rec := do(r, "/last-modified", map[string]string{
+			"If-Modified-Since": time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat),
+		})
+		require.Equal(t, http.StatusNotModified, rec.Code)
+
+		rec = do(r, "/last-modified", map[string]string{
+			"If-Modified-Since": time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat),
+		})
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	// Test case 5: stored response is served from the cache backend
+	t.Run("stored response is served from the cache backend", func(t *testing.T) {
+		var calls int32
+		r := newRouter(&calls)
+
+		rec := do(r, "/stored", map[string]string{"Accept-Language": "en"})
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "Accept-Language", rec.Header().Get("Vary"))
+
+		rec = do(r, "/stored", map[string]string{"Accept-Language": "en"})
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "hello", rec.Body.String())
+		require.NotEmpty(t, rec.Header().Get("Age"))
+		require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+		// Another value of the Vary header is a different cache entry.
+		do(r, "/stored", map[string]string{"Accept-Language": "de"})
+		require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+		// Authorized requests bypass the shared cache.
+		do(r, "/stored", map[string]string{"Accept-Language": "en", "Authorization": "Bearer token"})
+		require.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+}
+
+func TestMiddleware_PerRequestHeaders(t *testing.T) {
+	var calls int32
+	r := chi.NewRouter()
+	// Middlewares before the cache set per-request headers, e.g. CORS and CSRF
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Origin")
+			w.Header().Set("Access-Control-Allow-Origin", r.Header.Get("Origin"))
+			w.Header().Set("X-CSRF-Token", r.Header.Get("X-Test-Token"))
+			w.Header().Set("RateLimit-Remaining", "9")
+			next.ServeHTTP(w, r)
+		})
+	})
+	r.Use(httpcache.Middleware(httpcache.WithStore(cache.NewMemoryStore(0))))
+	r.With(httpcache.Use(httpcache.Policy{MaxAge: time.Minute, Store: true, Vary: []string{"Accept-Language", "origin"}})).
+		Get("/stored", func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.Write([]byte("hello")) // nolint:errcheck
+		})
+
+	// Test case 1: Vary values of other middlewares are kept
+	t.Run("vary is merged", func(t *testing.T) {
+		rec := do(r, "/stored", map[string]string{"Origin": "https://a.example.com", "X-Test-Token": "first"})
+		require.Equal(t, []string{"Origin", "Accept-Language"}, rec.Header().Values("Vary"))
+	})
+
+	// Test case 2: per-request headers are not replayed from the cache backend
+	t.Run("per-request headers are not replayed", func(t *testing.T) {
+		rec := do(r, "/stored", map[string]string{"Origin": "https://a.example.com", "X-Test-Token": "second"})
+		require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		require.NotEmpty(t, rec.Header().Get("Age"))
+		require.Equal(t, "second", rec.Header().Get("X-CSRF-Token"))
+		require.Equal(t, "https://a.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	// Test case 3: another origin is a different cache entry
+	t.Run("origin is a part of the key", func(t *testing.T) {
+		rec := do(r, "/stored", map[string]string{"Origin": "https://b.example.com"})
+		require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+		require.Equal(t, "https://b.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+		require.Empty(t, rec.Header().Get("X-CSRF-Token"))
+	})
+
+	// Test case 4: requests with cookies bypass the shared cache
+	t.Run("cookie bypasses the store", func(t *testing.T) {
+		do(r, "/stored", map[string]string{"Origin": "https://a.example.com", "Cookie": "sid=1"})
+		require.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+}
+
+func TestPolicy_CacheControl(t *testing.T) {
+	require.Equal(t, "no-store", httpcache.Policy{NoStore: true, MaxAge: time.Minute}.CacheControl())
+	require.Equal(t, "public, no-cache", httpcache.Policy{}.CacheControl())
+	require.Equal(t, "private, max-age=60, must-revalidate", httpcache.Policy{
+		Private: true, MaxAge: time.Minute, SharedMaxAge: time.Hour, MustRevalidate: true,
+	}.CacheControl())
+	require.Equal(t, "public, max-age=60, s-maxage=3600, stale-while-revalidate=30, immutable", httpcache.Policy{
+		MaxAge: time.Minute, SharedMaxAge: time.Hour, StaleWhileRevalidate: 30 * time.Second, Immutable: true,
+	}.CacheControl())
+}
@@ -0,0 +1,83 @@
+package httpcache
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy describes how a route response can be cached by clients and proxies.
+type Policy struct {
+	// MaxAge is the max-age directive. Zero means the response must be
+	// revalidated on every request (no-cache), but ETag still works.
+	MaxAge time.Duration
+	// SharedMaxAge is the s-maxage directive for shared caches.
+	SharedMaxAge time.Duration
+	// StaleWhileRevalidate is the stale-while-revalidate directive.
+	StaleWhileRevalidate time.Duration
+	// Private marks the response as private, so only the browser may cache it.
+	Private bool
+	// NoStore forbids any caching of the response.
+	NoStore bool
+	// MustRevalidate adds the must-revalidate directive.
+	MustRevalidate bool
+	// Immutable adds the immutable directive.
+	Immutable bool
+	// WeakETag makes the generated ETag weak (W/"...").
+	WeakETag bool
+	// Vary is the list of request headers the response depends on.
+	Vary []string
+	// Store enables storing the full response in the cache backend.
+	// Private responses and responses with cookies are never stored.
+	Store bool
+}
+
+// CacheControl returns the value of the Cache-Control header for the policy.
+func (p Policy) CacheControl() string {
+	if p.NoStore {
+		return "no-store"
+	}
+
+	directives := make([]string, 0, 6)
+	if p.Private {
+		directives = append(directives, "private")
+	} else {
+		directives = append(directives, "public")
+	}
+
+	if p.MaxAge > 0 {
+		directives = append(directives, "max-age="+seconds(p.MaxAge))
+	} else {
+		directives = append(directives, "no-cache")
+	}
+	if p.SharedMaxAge > 0 && !p.Private {
+		directives = append(directives, "s-maxage="+seconds(p.SharedMaxAge))
+	}
+	if p.StaleWhileRevalidate > 0 {
+		directives = append(directives, "stale-while-revalidate="+seconds(p.StaleWhileRevalidate))
+	}
+	if p.MustRevalidate {
+		directives = append(directives, "must-revalidate")
+	}
+	if p.Immutable {
+		directives = append(directives, "immutable")
+	}
+
+	return strings.Join(directives, ", ")
+}
+
+// storeTTL returns how long the response may be kept in the cache backend.
+func (p Policy) storeTTL() time.Duration {
+	if !p.Store || p.Private || p.NoStore {
+		return 0
+	}
+	if p.SharedMaxAge > 0 {
+		return p.SharedMaxAge
+	}
+	return p.MaxAge
+}
+
+// seconds formats the duration as a number of seconds.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(d/time.Second), 10)
+}
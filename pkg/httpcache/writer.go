@@ -0,0 +1,65 @@
+package httpcache
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// responseWriter defers the decision whether the response is cacheable
+// until the first write: responses of routes with a policy are buffered
+// to compute the ETag, all other responses are streamed with no-cache headers.
+type responseWriter struct {
+	http.ResponseWriter
+	state *state
+
+	status      int
+	wroteHeader bool
+	buffered    bool
+	buf         bytes.Buffer
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *responseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = status
+
+	if w.state.policy != nil && w.state.cacheable {
+		w.buffered = true
+		return
+	}
+
+	setNoCacheHeaders(w.Header())
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter.
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.buffered {
+		return w.buf.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher for streamed responses.
+func (w *responseWriter) Flush() {
+	if w.buffered {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
+		f.Flush()
+	}
+}
+
+// Unwrap returns the original http.ResponseWriter, used by http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
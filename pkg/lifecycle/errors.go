@@ -0,0 +1,16 @@
+package lifecycle
+
+import "errors"
+
+// Predefined errors.
+var (
+	ErrInvalidHook        = errors.New("hook must have a name and at least one of OnStart, Run or OnStop")
+	ErrDuplicateHook      = errors.New("hook with the same name is already registered")
+	ErrUnknownDependency  = errors.New("hook depends on an unknown hook")
+	ErrDependencyCycle    = errors.New("hook dependencies form a cycle")
+	ErrAlreadyRunning     = errors.New("lifecycle manager is already running")
+	ErrStartTimeout       = errors.New("hook did not start in time")
+	ErrStopTimeout        = errors.New("hook did not stop in time")
+	ErrUnexpectedExit     = errors.New("hook stopped unexpectedly")
+	ErrShutdownIncomplete = errors.New("some hooks failed to stop")
+)
@@ -0,0 +1,52 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// HTTPServer returns the hook which serves HTTP requests with srv.
+// The listener is opened on start, so a busy port fails the startup.
+// On stop the server stops accepting connections and waits for in-flight requests.
+func HTTPServer(name string, srv *http.Server, stopTimeout time.Duration, dependsOn ...string) Hook {
+	var ln net.Listener
+	return Hook{
+		Name:      name,
+		DependsOn: dependsOn,
+		OnStart: func(ctx context.Context) (err error) {
+			addr := srv.Addr
+			if addr == "" {
+				addr = ":http"
+			}
+			ln, err = (&net.ListenConfig{}).Listen(ctx, "tcp", addr)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", addr, err)
+			}
+			return nil
+		},
+		Run: func(ctx context.Context) error {
+			if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+		OnStop:      srv.Shutdown,
+		StopTimeout: stopTimeout,
+	}
+}
+
+// Closer returns the hook which closes c on stop, e.g. a database connection pool.
+func Closer(name string, c io.Closer, dependsOn ...string) Hook {
+	return Hook{
+		Name:      name,
+		DependsOn: dependsOn,
+		OnStop: func(context.Context) error {
+			return c.Close()
+		},
+	}
+}
@@ -0,0 +1,373 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type (
+	// Hook is a component of the application managed by the Manager,
+	// e.g. a database connection, an HTTP server or a queue worker.
+	Hook struct {
+		// Name identifies the hook in dependencies and logs.
+		Name string
+		// DependsOn lists the hooks which must be started before this one
+		// and stopped after it.
+		DependsOn []string
+		// OnStart starts the component. It must not block beyond the start timeout.
+		OnStart func(ctx context.Context) error
+		// Run runs the component in the background until its context is canceled.
+		// If it returns before shutdown, the whole application is stopped.
+		Run func(ctx context.Context) error
+		// OnStop stops the component, e.g. drains in-flight requests.
+		// It is called before the context of Run is canceled.
+		OnStop func(ctx context.Context) error
+		// StopTimeout overrides the stop timeout of the manager for this hook.
+		StopTimeout time.Duration
+	}
+
+	// Manager starts hooks in the order of their dependencies and stops them
+	// in the reverse order when the context is canceled or a hook fails.
+	//
+	// Hooks are grouped in phases by the depth of their dependencies.
+	// On shutdown the phases are stopped one after another, starting from the
+	// hooks nothing depends on, the hooks of the same phase are stopped concurrently.
+	Manager struct {
+		mu      sync.Mutex
+		hooks   []Hook
+		names   map[string]struct{}
+		running bool
+
+		logger       logrus.FieldLogger
+		startTimeout time.Duration
+		stopTimeout  time.Duration
+	}
+
+	// Option is a function that configures the Manager.
+	Option func(*Manager)
+
+	// state is the state of a started hook.
+	state struct {
+		hook     Hook
+		cancel   context.CancelFunc
+		done     chan error
+		stopping atomic.Bool
+	}
+)
+
+// New creates a new Manager instance.
+func New(opts ...Option) *Manager {
+	m := &Manager{
+		names:        make(map[string]struct{}),
+		logger:       logrus.StandardLogger(),
+		startTimeout: 15 * time.Second,
+		stopTimeout:  15 * time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// WithLogger sets the logger for the manager.
+// Default is the logrus standard logger.
+func WithLogger(l logrus.FieldLogger) Option {
+	return func(m *Manager) {
+		if l != nil {
+			m.logger = l
+		}
+	}
+}
+
+// WithStartTimeout sets the maximum duration of a single OnStart call.
+// Default is 15 seconds.
+func WithStartTimeout(d time.Duration) Option {
+	return func(m *Manager) {
+		if d > 0 {
+			m.startTimeout = d
+		}
+	}
+}
+
+// WithStopTimeout sets the default maximum duration of stopping a single hook.
+// Default is 15 seconds.
+func WithStopTimeout(d time.Duration) Option {
+	return func(m *Manager) {
+		if d > 0 {
+			m.stopTimeout = d
+		}
+	}
+}
+
+// Append registers the hooks. Dependencies are resolved by Run,
+// so hooks can be appended in any order.
+func (m *Manager) Append(hooks ...Hook) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running {
+		return ErrAlreadyRunning
+	}
+
+	for _, h := range hooks {
+		if h.Name == "" || (h.OnStart == nil && h.Run == nil && h.OnStop == nil) {
+			return fmt.Errorf("%w: %q", ErrInvalidHook, h.Name)
+		}
+		if _, ok := m.names[h.Name]; ok {
+			return fmt.Errorf("%w: %s", ErrDuplicateHook, h.Name)
+		}
+		m.names[h.Name] = struct{}{}
+		m.hooks = append(m.hooks, h)
+	}
+
+	return nil
+}
+
+// Run starts all hooks and blocks until the context is canceled or a hook fails,
+// then stops the started hooks. It returns the error which caused the shutdown,
+// if any, or ErrShutdownIncomplete if some hooks failed to stop.
+func (m *Manager) Run(ctx context.Context) error {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return ErrAlreadyRunning
+	}
+	m.running = true
+	hooks := m.hooks
+	m.mu.Unlock()
+
+	defer func() {
+		m.mu.Lock()
+		m.running = false
+		m.mu.Unlock()
+	}()
+
+	phases, err := resolve(hooks)
+	if err != nil {
+		return err
+	}
+
+	failures := make(chan error, len(hooks))
+	started := make([][]*state, 0, len(phases))
+
+	var cause error
+	for _, phase := range phases {
+		states := make([]*state, 0, len(phase))
+		for _, h := range phase {
+			s, err := m.start(ctx, h, failures)
+			if err != nil {
+				cause = err
+				break
+			}
+			states = append(states, s)
+		}
+		started = append(started, states)
+		if cause != nil {
+			break
+		}
+	}
+
+	if cause == nil {
+		m.logger.Info("All hooks started")
+		select {
+		case <-ctx.Done():
+			m.logger.Info("Context canceled, shutting down")
+		case cause = <-failures:
+			m.logger.WithError(cause).Error("Hook failed, shutting down")
+		}
+	}
+
+	if err := m.stop(started); err != nil && cause == nil {
+		cause = err
+	}
+
+	return cause
+}
+
+// start starts the hook and runs it in the background, if needed.
+func (m *Manager) start(ctx context.Context, h Hook, failures chan<- error) (*state, error) {
+	log := m.logger.WithField("hook", h.Name)
+	s := &state{hook: h}
+
+	if h.OnStart != nil {
+		log.Debug("Starting hook")
+		startCtx, cancel := context.WithTimeout(ctx, m.startTimeout)
+		err := h.OnStart(startCtx)
+		cancel()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				err = fmt.Errorf("%w: %s", ErrStartTimeout, m.startTimeout)
+			}
+			return nil, fmt.Errorf("failed to start %s: %w", h.Name, err)
+		}
+	}
+
+	if h.Run != nil {
+		var runCtx context.Context
+		runCtx, s.cancel = context.WithCancel(context.Background())
+		s.done = make(chan error, 1)
+		go func() {
+			err := h.Run(runCtx)
+			if !s.stopping.Load() {
+				if err == nil {
+					err = ErrUnexpectedExit
+				}
+				// The failure is the cause of the shutdown, not a stop error
+				failures <- fmt.Errorf("%s: %w", h.Name, err)
+				err = nil
+			}
+			s.done <- err
+		}()
+	}
+
+	log.Info("Hook started")
+	return s, nil
+}
+
+// stop stops the started hooks phase by phase in the reverse order.
+func (m *Manager) stop(phases [][]*state) error {
+	var mu sync.Mutex
+	var failed []string
+
+	for i := len(phases) - 1; i >= 0; i-- {
+		var wg sync.WaitGroup
+		for _, s := range phases[i] {
+			wg.Add(1)
+			go func(s *state) {
+				defer wg.Done()
+				if err := m.stopHook(s); err != nil {
+					mu.Lock()
+					failed = append(failed, s.hook.Name)
+					mu.Unlock()
+				}
+			}(s)
+		}
+		wg.Wait()
+	}
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("%w: %s", ErrShutdownIncomplete, strings.Join(failed, ", "))
+	}
+
+	m.logger.Info("All hooks stopped")
+	return nil
+}
+
+// stopHook stops the hook and waits for its Run to return within the stop timeout.
+// A hook which doesn't stop in time is logged and left behind.
+func (m *Manager) stopHook(s *state) error {
+	h := s.hook
+	log := m.logger.WithField("hook", h.Name)
+	s.stopping.Store(true)
+
+	timeout := h.StopTimeout
+	if timeout <= 0 {
+		timeout = m.stopTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	log.Debug("Stopping hook")
+	began := time.Now()
+
+	var err error
+	if h.OnStop != nil {
+		err = h.OnStop(ctx)
+	}
+
+	if s.cancel != nil {
+		s.cancel()
+		select {
+		case rerr := <-s.done:
+			if err == nil && rerr != nil && !errors.Is(rerr, context.Canceled) {
+				err = rerr
+			}
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.WithField("timeout", timeout).Error("Hook blocked shutdown, giving up waiting for it")
+		return fmt.Errorf("%s: %w", h.Name, ErrStopTimeout)
+	}
+	if err != nil {
+		log.WithError(err).Error("Failed to stop hook")
+		return fmt.Errorf("failed to stop %s: %w", h.Name, err)
+	}
+
+	log.WithField("duration", time.Since(began).String()).Info("Hook stopped")
+	return nil
+}
+
+// resolve groups the hooks in phases by the depth of their dependencies.
+// Hooks without dependencies are in the first phase, the order of registration
+// is kept within a phase.
+func resolve(hooks []Hook) ([][]Hook, error) {
+	index := make(map[string]int, len(hooks))
+	for i, h := range hooks {
+		index[h.Name] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make([]int, len(hooks))
+	depths := make([]int, len(hooks))
+
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		switch marks[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(append(path, hooks[i].Name), " -> "))
+		}
+		marks[i] = visiting
+		path = append(path, hooks[i].Name)
+
+		for _, dep := range hooks[i].DependsOn {
+			j, ok := index[dep]
+			if !ok {
+				return fmt.Errorf("%w: %s depends on %s", ErrUnknownDependency, hooks[i].Name, dep)
+			}
+			if err := visit(j, path); err != nil {
+				return err
+			}
+			if depths[j]+1 > depths[i] {
+				depths[i] = depths[j] + 1
+			}
+		}
+
+		marks[i] = visited
+		return nil
+	}
+
+	var phases [][]Hook
+	for i := range hooks {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+	for i, h := range hooks {
+		for len(phases) <= depths[i] {
+			phases = append(phases, nil)
+		}
+		phases[depths[i]] = append(phases[depths[i]], h)
+	}
+
+	return phases, nil
+}
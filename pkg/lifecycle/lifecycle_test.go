@@ -0,0 +1,321 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dmitrymomot/go-app/pkg/lifecycle"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder records the order of hook events.
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) list() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+// hook returns a hook which records its start and stop.
+func (r *recorder) hook(name string, dependsOn ...string) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name:      name,
+		DependsOn: dependsOn,
+		OnStart: func(context.Context) error {
+			r.add("start " + name)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			r.add("stop " + name)
+			return nil
+		},
+	}
+}
+
+func newManager(opts ...lifecycle.Option) *lifecycle.Manager {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return lifecycle.New(append([]lifecycle.Option{lifecycle.WithLogger(logger)}, opts...)...)
+}
+
+// runUntilStarted runs the manager and cancels its context once all hooks are started.
+func runUntilStarted(t *testing.T, m *lifecycle.Manager, started <-chan struct{}) error {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errC := make(chan error, 1)
+	go func() { errC <- m.Run(ctx) }()
+
+	select {
+	case <-started:
+	case err := <-errC:
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("hooks did not start")
+	}
+	cancel()
+	return <-errC
+}
+
+func TestManager_Append(t *testing.T) {
+	noop := func(context.Context) error { return nil }
+
+	// Test case 1: invalid hooks
+	t.Run("invalid hooks", func(t *testing.T) {
+		m := newManager()
+		require.ErrorIs(t, m.Append(lifecycle.Hook{OnStop: noop}), lifecycle.ErrInvalidHook)
+		require.ErrorIs(t, m.Append(lifecycle.Hook{Name: "db"}), lifecycle.ErrInvalidHook)
+	})
+
+	// Test case 2: duplicate hook
+	t.Run("duplicate hook", func(t *testing.T) {
+		m := newManager()
+		require.NoError(t, m.Append(lifecycle.Hook{Name: "db", OnStop: noop}))
+		require.ErrorIs(t, m.Append(lifecycle.Hook{Name: "db", OnStop: noop}), lifecycle.ErrDuplicateHook)
+	})
+}
+
+func TestManager_Run(t *testing.T) {
+	// Test case 1: hooks are started in the order of dependencies and stopped in reverse
+	t.Run("order", func(t *testing.T) {
+		r := &recorder{}
+		started := make(chan struct{})
+		m := newManager()
+		require.NoError(t, m.Append(
+			r.hook("readiness", "http"),
+			r.hook("http", "db", "redis"),
+			r.hook("db"),
+			r.hook("redis"),
+			lifecycle.Hook{
+				Name:      "started",
+				DependsOn: []string{"readiness"},
+				OnStart: func(context.Context) error {
+					close(started)
+					return nil
+				},
+			},
+		))
+
+		require.NoError(t, runUntilStarted(t, m, started))
+
+		events := r.list()
+		require.Equal(t, []string{"start db", "start redis", "start http", "start readiness"}, events[:4])
+		require.Equal(t, []string{"stop readiness", "stop http"}, events[4:6])
+		require.ElementsMatch(t, []string{"stop db", "stop redis"}, events[6:])
+	})
+
+	// Test case 2: invalid dependencies
+	t.Run("invalid dependencies", func(t *testing.T) {
+		r := &recorder{}
+
+		m := newManager()
+		require.NoError(t, m.Append(r.hook("http", "db")))
+		require.ErrorIs(t, m.Run(context.Background()), lifecycle.ErrUnknownDependency)
+
+		m = newManager()
+		require.NoError(t, m.Append(r.hook("a", "c"), r.hook("b", "a"), r.hook("c", "b")))
+		err := m.Run(context.Background())
+		require.ErrorIs(t, err, lifecycle.ErrDependencyCycle)
+		require.Contains(t, err.Error(), "a -> c -> b -> a")
+
+		require.Empty(t, r.list())
+	})
+
+	// Test case 3: failed start stops the started hooks
+	t.Run("failed start", func(t *testing.T) {
+		r := &recorder{}
+		failure := errors.New("connection refused")
+		m := newManager()
+		require.NoError(t, m.Append(
+			r.hook("db"),
+			lifecycle.Hook{
+				Name:      "http",
+				DependsOn: []string{"db"},
+				OnStart:   func(context.Context) error { return failure },
+			},
+			r.hook("readiness", "http"),
+		))
+
+		require.ErrorIs(t, m.Run(context.Background()), failure)
+		require.Equal(t, []string{"start db", "stop db"}, r.list())
+	})
+
+	// Test case 4: failed run stops the application
+	t.Run("failed run", func(t *testing.T) {
+		r := &recorder{}
+		failure := errors.New("queue is gone")
+		m := newManager()
+		require.NoError(t, m.Append(
+			r.hook("db"),
+			lifecycle.Hook{
+				Name:      "worker",
+				DependsOn: []string{"db"},
+				Run:       func(context.Context) error { return failure },
+			},
+			lifecycle.Hook{
+				Name:      "scheduler",
+				DependsOn: []string{"db"},
+				Run: func(ctx context.Context) error {
+					<-ctx.Done()
+					r.add("stop scheduler")
+					return nil
+				},
+			},
+		))
+
+		err := m.Run(context.Background())
+		require.ErrorIs(t, err, failure)
+		require.Contains(t, err.Error(), "worker")
+		require.Equal(t, []string{"start db", "stop scheduler", "stop db"}, r.list())
+	})
+
+	// Test case 5: hook which returns early without an error
+	t.Run("unexpected exit", func(t *testing.T) {
+		m := newManager()
+		require.NoError(t, m.Append(lifecycle.Hook{
+			Name: "worker",
+			Run:  func(context.Context) error { return nil },
+		}))
+		require.ErrorIs(t, m.Run(context.Background()), lifecycle.ErrUnexpectedExit)
+	})
+
+	// Test case 6: blocked hook doesn't block the shutdown of other hooks
+	t.Run("stop timeout", func(t *testing.T) {
+		r := &recorder{}
+		started := make(chan struct{})
+		release := make(chan struct{})
+		defer close(release)
+
+		m := newManager(lifecycle.WithStopTimeout(time.Second))
+		require.NoError(t, m.Append(
+			r.hook("db"),
+			lifecycle.Hook{
+				Name:      "worker",
+				DependsOn: []string{"db"},
+				Run: func(context.Context) error {
+					close(started)
+					<-release
+					return nil
+				},
+				StopTimeout: 50 * time.Millisecond,
+			},
+		))
+
+		err := runUntilStarted(t, m, started)
+		require.ErrorIs(t, err, lifecycle.ErrShutdownIncomplete)
+		require.Contains(t, err.Error(), "worker")
+		require.Equal(t, []string{"start db", "stop db"}, r.list())
+	})
+
+	// Test case 7: manager is not reentrant
+	t.Run("already running", func(t *testing.T) {
+		started := make(chan struct{})
+		m := newManager()
+		require.NoError(t, m.Append(lifecycle.Hook{
+			Name: "worker",
+			Run: func(ctx context.Context) error {
+				close(started)
+				<-ctx.Done()
+				return nil
+			},
+		}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		errC := make(chan error, 1)
+		go func() { errC <- m.Run(ctx) }()
+		<-started
+
+		require.ErrorIs(t, m.Run(ctx), lifecycle.ErrAlreadyRunning)
+		require.ErrorIs(t, m.Append(lifecycle.Hook{Name: "db", OnStop: func(context.Context) error { return nil }}), lifecycle.ErrAlreadyRunning)
+
+		cancel()
+		require.NoError(t, <-errC)
+	})
+}
+
+func TestHTTPServer(t *testing.T) {
+	// Test case 1: in-flight requests are completed on shutdown
+	t.Run("drains in-flight requests", func(t *testing.T) {
+		inFlight := make(chan struct{})
+		srv := &http.Server{
+			Addr: freeAddr(t),
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				close(inFlight)
+				time.Sleep(100 * time.Millisecond)
+				fmt.Fprint(w, "done")
+			}),
+		}
+
+		m := newManager()
+		require.NoError(t, m.Append(lifecycle.HTTPServer("http", srv, time.Second)))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		errC := make(chan error, 1)
+		go func() { errC <- m.Run(ctx) }()
+
+		bodyC := make(chan string, 1)
+		go func() {
+			// The listener is bound on start, so the request may only race with the start itself
+			var resp *http.Response
+			var err error
+			for i := 0; i < 100; i++ {
+				if resp, err = http.Get("http://" + srv.Addr); err == nil {
+					break
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			if err != nil {
+				bodyC <- err.Error()
+				return
+			}
+			defer resp.Body.Close()
+			b, _ := io.ReadAll(resp.Body)
+			bodyC <- string(b)
+		}()
+
+		<-inFlight
+		cancel()
+		require.NoError(t, <-errC)
+		require.Equal(t, "done", <-bodyC)
+	})
+
+	// Test case 2: busy port fails the startup
+	t.Run("busy port", func(t *testing.T) {
+		addr := freeAddr(t)
+		first := lifecycle.HTTPServer("first", &http.Server{Addr: addr, Handler: http.NotFoundHandler()}, time.Second)
+		require.NoError(t, first.OnStart(context.Background()))
+		go first.Run(context.Background())       // nolint:errcheck
+		defer first.OnStop(context.Background()) // nolint:errcheck
+
+		second := lifecycle.HTTPServer("second", &http.Server{Addr: addr}, time.Second)
+		require.Error(t, second.OnStart(context.Background()))
+	})
+}
+
+// freeAddr returns a local address with a free port.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	return ln.Addr().String()
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+type (
+	// ctxKey is the context key type of the package.
+	ctxKey struct{}
+
+	// requestState is the per-request logger state shared between middlewares,
+	// so values set by inner middlewares are visible in the access log.
+	requestState struct {
+		mu      sync.RWMutex
+		entry   *logrus.Entry
+		subject string
+	}
+)
+
+// NewContext returns the context carrying the logger.
+func NewContext(ctx context.Context, l logrus.FieldLogger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &requestState{entry: toEntry(l)})
+}
+
+// FromContext returns the request-scoped logger from the context
+// with the route pattern and subject fields, if they are known.
+// The standard logger is returned if the context has no logger.
+func FromContext(ctx context.Context) logrus.FieldLogger {
+	st, ok := ctx.Value(ctxKey{}).(*requestState)
+	if !ok {
+		return logrus.StandardLogger().WithContext(ctx)
+	}
+	return st.logger(chi.RouteContext(ctx)).WithContext(ctx)
+}
+
+// SetSubject sets the authenticated subject (e.g. user ID) of the request logger.
+// Call it from the authentication middleware, it's visible to the outer access log too.
+func SetSubject(ctx context.Context, subject string) {
+	if st, ok := ctx.Value(ctxKey{}).(*requestState); ok {
+		st.mu.Lock()
+		st.subject = subject
+		st.mu.Unlock()
+	}
+}
+
+// logger returns the entry with the route and subject fields.
+func (st *requestState) logger(rctx *chi.Context) *logrus.Entry {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+
+	fields := logrus.Fields{}
+	if rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			fields["route"] = pattern
+		}
+	}
+	if st.subject != "" {
+		fields["subject"] = st.subject
+	}
+
+	return st.entry.WithFields(fields)
+}
+
+// toEntry converts the field logger to an entry.
+func toEntry(l logrus.FieldLogger) *logrus.Entry {
+	switch v := l.(type) {
+	case *logrus.Entry:
+		return v
+	case *logrus.Logger:
+		return logrus.NewEntry(v)
+	case nil:
+		return logrus.NewEntry(logrus.StandardLogger())
+	default:
+		return v.WithFields(logrus.Fields{})
+	}
+}
@@ -0,0 +1,9 @@
+package logger
+
+import "errors"
+
+// Predefined errors.
+var (
+	ErrUnknownFormat = errors.New("unknown log format")
+	ErrUnknownLevel  = errors.New("unknown log level")
+)
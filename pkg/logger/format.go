@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Supported log formats.
+const (
+	// FormatJSON is one JSON object per line, for production and log collectors.
+	FormatJSON = "json"
+	// FormatLogfmt is key=value pairs without colors.
+	FormatLogfmt = "logfmt"
+	// FormatText is a human-readable colored text, for local development.
+	FormatText = "text"
+)
+
+// NewFormatter returns the logrus formatter of the format: json, logfmt or text.
+func NewFormatter(format string) (logrus.Formatter, error) {
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		return &logrus.JSONFormatter{}, nil
+	case FormatLogfmt:
+		return &logrus.TextFormatter{DisableColors: true, FullTimestamp: true}, nil
+	case FormatText:
+		return &logrus.TextFormatter{ForceColors: true, FullTimestamp: true}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+}
@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/dmitrymomot/go-pkg/response"
+	"github.com/sirupsen/logrus"
+)
+
+// errMethodNotAllowed is returned for unsupported methods of the level handler.
+var errMethodNotAllowed = errors.New("method_not_allowed")
+
+// levelPayload is the request and response body of the level handler.
+type levelPayload struct {
+	Level string `json:"level"`
+}
+
+// LevelHandler returns the handler to get and change the log level at runtime.
+// GET responds with the current level, PUT or POST with {"level": "debug"} body sets a new one.
+// The handler must be protected, e.g. served on an internal port with authentication.
+func LevelHandler(l *logrus.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut, http.MethodPost:
+			var p levelPayload
+			if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+				response.JSON(w, response.NewError(http.StatusBadRequest, err, "Invalid request body.", nil)) // nolint:errcheck
+				return
+			}
+			lvl, err := ParseLevel(p.Level)
+			if err != nil {
+				response.JSON(w, response.NewError(http.StatusBadRequest, err, "Invalid log level.", nil)) // nolint:errcheck
+				return
+			}
+			if prev := l.GetLevel(); prev != lvl {
+				l.SetLevel(lvl)
+				l.WithFields(logrus.Fields{"from": prev.String(), "to": lvl.String()}).Warn("Log level changed")
+			}
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT, POST")
+			response.JSON(w, response.NewError(http.StatusMethodNotAllowed, errMethodNotAllowed, "Method not allowed.", nil)) // nolint:errcheck
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_ = json.NewEncoder(w).Encode(levelPayload{Level: l.GetLevel().String()})
+	}
+}
+
+// ParseLevel parses the log level name.
+func ParseLevel(level string) (logrus.Level, error) {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownLevel, level)
+	}
+	return lvl, nil
+}
+
+// shiftLevel returns the level more (delta > 0) or less (delta < 0) verbose than the given one,
+// bounded by the panic and trace levels.
+func shiftLevel(lvl logrus.Level, delta int) logrus.Level {
+	next := int(lvl) + delta
+	if next < int(logrus.PanicLevel) {
+		next = int(logrus.PanicLevel)
+	}
+	if next > int(logrus.TraceLevel) {
+		next = int(logrus.TraceLevel)
+	}
+	return logrus.Level(next)
+}
@@ -0,0 +1,61 @@
+package logger_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dmitrymomot/go-app/pkg/logger"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelHandler(t *testing.T) {
+	l, _ := newLogger()
+	l.SetLevel(logrus.InfoLevel)
+	h := logger.LevelHandler(l)
+
+	// Test case 1: get the current level
+	t.Run("get the current level", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"level":"info"}`, w.Body.String())
+	})
+
+	// Test case 2: change the level
+	t.Run("change the level", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"level":"debug"}`)))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"level":"debug"}`, w.Body.String())
+		require.Equal(t, logrus.DebugLevel, l.GetLevel())
+	})
+
+	// Test case 3: unknown level is rejected
+	t.Run("unknown level is rejected", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"level":"loud"}`)))
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Equal(t, logrus.DebugLevel, l.GetLevel())
+	})
+
+	// Test case 4: unsupported method
+	t.Run("unsupported method", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/", nil))
+		require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
+
+func TestNewFormatter(t *testing.T) {
+	for _, format := range []string{logger.FormatJSON, logger.FormatLogfmt, logger.FormatText, "JSON"} {
+		f, err := logger.NewFormatter(format)
+		require.NoError(t, err)
+		require.NotNil(t, f)
+	}
+
+	_, err := logger.NewFormatter("xml")
+	require.ErrorIs(t, err, logger.ErrUnknownFormat)
+}
@@ -0,0 +1,138 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dmitrymomot/go-app/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func newLogger() (*logrus.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	l := logrus.New()
+	l.SetOutput(&buf)
+	l.SetFormatter(&logrus.JSONFormatter{})
+	l.SetLevel(logrus.DebugLevel)
+	return l, &buf
+}
+
+func lines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var result []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &m))
+		result = append(result, m)
+	}
+	return result
+}
+
+func newRouter(l *logrus.Logger, opts ...logger.Option) *chi.Mux {
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID, logger.Middleware(l, opts...))
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		logger.SetSubject(r.Context(), "user-1")
+		logger.FromContext(r.Context()).Debug("Loading user")
+		_, _ = w.Write([]byte("hello"))
+	})
+	r.Get("/health", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	r.Get("/fail", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	r.Get("/panic", func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	})
+	return r
+}
+
+func TestMiddleware(t *testing.T) {
+	// Test case 1: request-scoped logger and access log
+	t.Run("request-scoped logger and access log", func(t *testing.T) {
+		l, buf := newLogger()
+		newRouter(l).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+		entries := lines(t, buf)
+		require.Len(t, entries, 2)
+
+		handlerLog, accessLog := entries[0], entries[1]
+		require.Equal(t, "Loading user", handlerLog["msg"])
+		require.Equal(t, "/users/{id}", handlerLog["route"])
+		require.Equal(t, "user-1", handlerLog["subject"])
+		require.NotEmpty(t, handlerLog["request_id"])
+
+		require.Equal(t, "Request handled", accessLog["msg"])
+		require.Equal(t, handlerLog["request_id"], accessLog["request_id"])
+		require.Equal(t, "GET", accessLog["method"])
+		require.Equal(t, "/users/{id}", accessLog["route"])
+		require.Equal(t, "user-1", accessLog["subject"])
+		require.EqualValues(t, 200, accessLog["status"])
+		require.EqualValues(t, 5, accessLog["bytes"])
+		require.NotEmpty(t, accessLog["duration"])
+		require.NotEmpty(t, accessLog["remote_ip"])
+	})
+
+	// Test case 2: excluded paths are not logged
+	t.Run("excluded paths are not logged", func(t *testing.T) {
+		l, buf := newLogger()
+		newRouter(l, logger.WithExcludedPaths("/health")).
+			ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+		require.Empty(t, buf.String())
+	})
+
+	// Test case 3: sampling skips successful requests only
+	t.Run("sampling skips successful requests only", func(t *testing.T) {
+		l, buf := newLogger()
+		l.SetLevel(logrus.InfoLevel)
+		r := newRouter(l, logger.WithSampleRate(0))
+
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+		require.Empty(t, buf.String())
+
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+		entries := lines(t, buf)
+		require.Len(t, entries, 1)
+		require.Equal(t, "warning", entries[0]["level"])
+	})
+
+	// Test case 4: panicked request is logged and the panic is passed on
+	t.Run("panicked request is logged", func(t *testing.T) {
+		l, buf := newLogger()
+		r := newRouter(l)
+
+		require.Panics(t, func() {
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+		})
+		entries := lines(t, buf)
+		require.Len(t, entries, 1)
+		require.EqualValues(t, 500, entries[0]["status"])
+		require.Equal(t, "error", entries[0]["level"])
+	})
+}
+
+func TestFromContext(t *testing.T) {
+	l, buf := newLogger()
+	ctx := logger.NewContext(context.Background(), l.WithField("component", "test"))
+	logger.SetSubject(ctx, "user-2")
+	logger.FromContext(ctx).Info("hello")
+
+	entries := lines(t, buf)
+	require.Len(t, entries, 1)
+	require.Equal(t, "test", entries[0]["component"])
+	require.Equal(t, "user-2", entries[0]["subject"])
+
+	// Without logger in the context
+	require.NotNil(t, logger.FromContext(context.Background()))
+}
@@ -0,0 +1,153 @@
+package logger
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dmitrymomot/go-app/pkg/tracing"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/sirupsen/logrus"
+)
+
+type (
+	// Option is a function that configures the middleware.
+	Option func(*options)
+
+	options struct {
+		subject    func(r *http.Request) string
+		sampleRate float64
+		exclude    []string
+		accessLog  bool
+	}
+)
+
+// Middleware stores the request-scoped logger in the request context, see FromContext,
+// and writes an access log line with status, bytes and duration when the request is done.
+// The logger has request_id, method, path, remote_ip and trace fields,
+// route and subject fields are added as soon as they are known.
+// Use it after middleware.RealIP, middleware.RequestID and tracing.Middleware.
+func Middleware(base logrus.FieldLogger, opts ...Option) func(http.Handler) http.Handler {
+	o := &options{
+		sampleRate: 1,
+		accessLog:  true,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			entry := toEntry(base).WithFields(logrus.Fields{
+				"request_id": middleware.GetReqID(ctx),
+				"method":     r.Method,
+				"path":       r.URL.Path,
+				"remote_ip":  r.RemoteAddr,
+			}).WithFields(tracing.LogFields(ctx))
+
+			st := &requestState{entry: entry}
+			if o.subject != nil {
+				st.subject = o.subject(r)
+			}
+			ctx = context.WithValue(ctx, ctxKey{}, st)
+
+			if !o.accessLog || o.excluded(r.URL.Path) {
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			start := time.Now()
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			defer func() {
+				status := ww.Status()
+				if rec := recover(); rec != nil {
+					// Log the panicked request and pass the panic to the recoverer.
+					defer panic(rec)
+					status = http.StatusInternalServerError
+				} else if status == 0 {
+					status = http.StatusOK
+				}
+
+				if status < http.StatusBadRequest && !o.sampled() {
+					return
+				}
+
+				l := st.logger(chi.RouteContext(ctx)).WithFields(logrus.Fields{
+					"status":   status,
+					"bytes":    ww.BytesWritten(),
+					"duration": time.Since(start).String(),
+				})
+				switch {
+				case status >= http.StatusInternalServerError:
+					l.Error("Request failed")
+				case status >= http.StatusBadRequest:
+					l.Warn("Request rejected")
+				default:
+					l.Info("Request handled")
+				}
+			}()
+
+			next.ServeHTTP(ww, r.WithContext(ctx))
+		})
+	}
+}
+
+// WithSubject sets the function returning the subject of the request known before the handler,
+// e.g. from a header. Use SetSubject to set the subject from an inner middleware.
+func WithSubject(fn func(r *http.Request) string) Option {
+	return func(o *options) {
+		o.subject = fn
+	}
+}
+
+// WithSampleRate sets the share of successful requests written to the access log, from 0 to 1.
+// Requests with 4xx and 5xx status codes are always logged.
+// Default is 1, i.e. all requests are logged.
+func WithSampleRate(rate float64) Option {
+	return func(o *options) {
+		if rate >= 0 && rate <= 1 {
+			o.sampleRate = rate
+		}
+	}
+}
+
+// WithExcludedPaths disables the access log of the paths, e.g. health checks.
+// A path ending with /* excludes the prefix.
+// The request-scoped logger is still available for the excluded paths.
+func WithExcludedPaths(paths ...string) Option {
+	return func(o *options) {
+		o.exclude = append(o.exclude, paths...)
+	}
+}
+
+// WithoutAccessLog disables the access log, only the request-scoped logger is set up.
+func WithoutAccessLog() Option {
+	return func(o *options) {
+		o.accessLog = false
+	}
+}
+
+// excluded reports whether the access log of the path is disabled.
+func (o *options) excluded(path string) bool {
+	for _, p := range o.exclude {
+		if strings.HasSuffix(p, "/*") {
+			prefix := strings.TrimSuffix(p, "/*")
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				return true
+			}
+		} else if path == p {
+			return true
+		}
+	}
+	return false
+}
+
+// sampled reports whether the successful request is written to the access log.
+func (o *options) sampled() bool {
+	return o.sampleRate >= 1 || (o.sampleRate > 0 && rand.Float64() < o.sampleRate) //nolint:gosec // sampling doesn't need a secure random
+}
@@ -0,0 +1,41 @@
+//go:build !windows
+
+package logger
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WatchLevelSignals changes the log level on signals until the context is canceled:
+// SIGUSR1 makes the logger one level more verbose (e.g. info -> debug),
+// SIGUSR2 makes it one level less verbose (e.g. info -> warning).
+func WatchLevelSignals(ctx context.Context, l *logrus.Logger) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGUSR1, syscall.SIGUSR2)
+	defer signal.Stop(sig)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case s := <-sig:
+			delta := 1
+			if s == syscall.SIGUSR2 {
+				delta = -1
+			}
+			prev := l.GetLevel()
+			next := shiftLevel(prev, delta)
+			l.SetLevel(next)
+			l.WithFields(logrus.Fields{
+				"from":   prev.String(),
+				"to":     next.String(),
+				"signal": s.String(),
+			}).Warn("Log level changed")
+		}
+	}
+}
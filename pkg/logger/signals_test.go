@@ -0,0 +1,39 @@
+//go:build !windows
+
+package logger_test
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/dmitrymomot/go-app/pkg/logger"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchLevelSignals(t *testing.T) {
+	l, _ := newLogger()
+	l.SetLevel(logrus.InfoLevel)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		logger.WatchLevelSignals(ctx, l)
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond) // wait for signal.Notify
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	require.Eventually(t, func() bool { return l.GetLevel() == logrus.DebugLevel }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+	require.Eventually(t, func() bool { return l.GetLevel() == logrus.InfoLevel }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+	require.Eventually(t, func() bool { return l.GetLevel() == logrus.WarnLevel }, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package logger
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WatchLevelSignals is a no-op on Windows, which has no SIGUSR1 and SIGUSR2 signals.
+// It blocks until the context is canceled.
+func WatchLevelSignals(ctx context.Context, _ *logrus.Logger) {
+	<-ctx.Done()
+}
@@ -0,0 +1,140 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"runtime"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type (
+	// Metrics is a registry of the application metrics.
+	Metrics struct {
+		registry  *prometheus.Registry
+		namespace string
+		buckets   []float64
+
+		appName  string
+		buildTag string
+		dbs      map[string]*sql.DB
+
+		requests *prometheus.CounterVec
+		duration *prometheus.HistogramVec
+		inFlight prometheus.Gauge
+	}
+
+	// Option is a function that configures the metrics.
+	Option func(*Metrics)
+)
+
+// New creates a new metrics registry with Go runtime, process, build info and HTTP metrics.
+func New(opts ...Option) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		buckets:  prometheus.DefBuckets,
+		appName:  "app",
+		buildTag: "undefined",
+		dbs:      make(map[string]*sql.DB),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	m.requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: m.namespace,
+		Name:      "http_requests_total",
+		Help:      "Total number of HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+	m.duration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: m.namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of HTTP requests by method, route pattern and status code.",
+		Buckets:   m.buckets,
+	}, []string{"method", "route", "status"})
+	m.inFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: m.namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "Number of HTTP requests being served.",
+	})
+	buildInfo := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: m.namespace,
+		Name:      "build_info",
+		Help:      "Build information, the value is always 1.",
+		ConstLabels: prometheus.Labels{
+			"app":        m.appName,
+			"build_tag":  m.buildTag,
+			"go_version": runtime.Version(),
+		},
+	}, func() float64 { return 1 })
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(collectors.WithGoCollectorRuntimeMetrics(collectors.MetricsAll)),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{Namespace: m.namespace}),
+		buildInfo,
+		m.requests,
+		m.duration,
+		m.inFlight,
+	)
+	for name, db := range m.dbs {
+		m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+	}
+
+	return m
+}
+
+// Registry returns the registry to register custom metrics.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler returns the handler exposing metrics in Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
+		Registry:          m.registry,
+		EnableOpenMetrics: true,
+	})
+}
+
+// WithNamespace sets the prefix of the application metric names.
+// Default is no prefix.
+func WithNamespace(ns string) Option {
+	return func(m *Metrics) {
+		m.namespace = ns
+	}
+}
+
+// WithBuildInfo sets the labels of the build_info metric.
+// The build tag is usually the commit hash.
+func WithBuildInfo(appName, buildTag string) Option {
+	return func(m *Metrics) {
+		if appName != "" {
+			m.appName = appName
+		}
+		if buildTag != "" {
+			m.buildTag = buildTag
+		}
+	}
+}
+
+// WithBuckets sets the buckets of the HTTP request duration histogram, in seconds.
+// Default is prometheus.DefBuckets.
+func WithBuckets(buckets []float64) Option {
+	return func(m *Metrics) {
+		if len(buckets) > 0 {
+			m.buckets = buckets
+		}
+	}
+}
+
+// WithDB adds connection pool metrics of the database, see sql.DBStats.
+// The name is used as the db_name label.
+func WithDB(name string, db *sql.DB) Option {
+	return func(m *Metrics) {
+		if db != nil {
+			m.dbs[name] = db
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package metrics_test
+
+import (
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dmitrymomot/go-app/pkg/metrics"
+	"github.com/go-chi/chi/v5"
+	_ "github.com/lib/pq" // init pg driver
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMiddleware(t *testing.T) {
+	m := metrics.New(metrics.WithNamespace("test"))
+
+	r := chi.NewRouter()
+	r.Use(m.Middleware)
+	r.Get("/users/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	r.Route("/api", func(r chi.Router) {
+		r.Post("/items", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		})
+	})
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/users/1", nil),
+		httptest.NewRequest(http.MethodGet, "/users/2", nil),
+		httptest.NewRequest(http.MethodPost, "/api/items", nil),
+		httptest.NewRequest(http.MethodGet, "/not/found/123", nil),
+		httptest.NewRequest("RANDOM123", "/users/1", nil),
+	} {
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	body := scrape(t, m)
+	require.Contains(t, body, `test_http_requests_total{method="GET",route="/users/{id}",status="200"} 2`)
+	require.Contains(t, body, `test_http_requests_total{method="POST",route="/api/items",status="201"} 1`)
+	require.Contains(t, body, `test_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	require.Contains(t, body, `test_http_request_duration_seconds_count{method="GET",route="/users/{id}",status="200"} 2`)
+	require.Contains(t, body, `test_http_requests_in_flight 0`)
+	require.Contains(t, body, `test_http_requests_total{method="OTHER",route="unmatched",status="405"} 1`)
+	require.NotContains(t, body, "/users/1")
+	require.NotContains(t, body, "RANDOM123")
+}
+
+func TestNew(t *testing.T) {
+	db, err := sql.Open("postgres", "postgres://localhost/test")
+	require.NoError(t, err)
+	defer db.Close()
+
+	m := metrics.New(
+		metrics.WithBuildInfo("go-app", "abc123"),
+		metrics.WithDB("main", db),
+	)
+	body := scrape(t, m)
+
+	require.Contains(t, body, `build_info{app="go-app",build_tag="abc123",go_version="`)
+	require.Contains(t, body, `go_sched_goroutines_goroutines`)
+	require.Contains(t, body, `go_sql_max_open_connections{db_name="main"}`)
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "build_info") {
+			require.True(t, strings.HasSuffix(line, " 1"), line)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// unmatchedRoute is the route label of requests not matched by any route,
+// raw paths are not used to keep the labels cardinality low.
+const unmatchedRoute = "unmatched"
+
+// otherMethod is the method label of requests with a non-standard method,
+// which is set by the client and would make the labels cardinality unbounded.
+const otherMethod = "OTHER"
+
+// Middleware records the number and duration of HTTP requests
+// by method, chi route pattern and status code.
+// Use it as the first middleware of the router to count recovered panics and rejected requests.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.inFlight.Inc()
+		defer m.inFlight.Dec()
+
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		defer func() {
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			labels := []string{methodLabel(r.Method), routePattern(r), strconv.Itoa(status)}
+			m.requests.WithLabelValues(labels...).Inc()
+			m.duration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+		}()
+
+		next.ServeHTTP(ww, r)
+	})
+}
+
+// methodLabel returns the method if it is a standard one, otherwise "OTHER".
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return otherMethod
+}
+
+// routePattern returns the matched chi route pattern, e.g. /users/{id}.
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return unmatchedRoute
+	}
+	if pattern := rctx.RoutePattern(); pattern != "" {
+		return pattern
+	}
+	return unmatchedRoute
+}
@@ -0,0 +1,182 @@
+package migrator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// VerifyError is a list of applied migrations which differ from the source.
+type VerifyError []error
+
+// Error implements the error interface.
+func (e VerifyError) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return "migrations verification failed: " + strings.Join(msgs, "; ")
+}
+
+// Is reports whether any of the problems matches the target error.
+func (e VerifyError) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Checksum returns the content hash of the migration.
+// Statements are trimmed, so formatting around them doesn't change the hash.
+func Checksum(m *migrate.Migration) string {
+	h := sha256.New()
+	write := func(section string, disableTx bool, queries []string) {
+		fmt.Fprintf(h, "-- %s notransaction=%t\n", section, disableTx)
+		for _, q := range queries {
+			fmt.Fprintf(h, "%s\n", strings.TrimSpace(q))
+		}
+	}
+	write("up", m.DisableTransactionUp, m.Up)
+	write("down", m.DisableTransactionDown, m.Down)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Verify checks that all applied migrations are present in the source
+// and were not changed since they were applied.
+// Migrations applied before checksums were recorded are not compared,
+// their checksums are recorded by the next Up.
+// It returns VerifyError with all found problems.
+func (m *Migrator) Verify() error {
+	migrations, err := m.source.FindMigrations()
+	if err != nil {
+		return fmt.Errorf("failed to find migrations: %w", err)
+	}
+	records, err := m.set.GetMigrationRecords(m.db, dialect)
+	if err != nil {
+		return fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	checksums, err := m.checksums()
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[string]*migrate.Migration, len(migrations))
+	for _, mg := range migrations {
+		byID[mg.Id] = mg
+	}
+
+	var errs VerifyError
+	for _, r := range records {
+		mg, ok := byID[r.Id]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrMissingMigration, r.Id))
+			continue
+		}
+		if sum, ok := checksums[r.Id]; ok && sum != Checksum(mg) {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrChecksumMismatch, r.Id))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// checksumsTable returns the quoted name of the table storing checksums of applied migrations.
+func (m *Migrator) checksumsTable() string {
+	return pq.QuoteIdentifier(m.set.TableName + "_checksums")
+}
+
+// checksums returns the recorded checksums of applied migrations by migration id.
+func (m *Migrator) checksums() (map[string]string, error) {
+	if err := m.createChecksumsTable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.Query("SELECT id, checksum FROM " + m.checksumsTable())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get migration checksums: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]string)
+	for rows.Next() {
+		var id, sum string
+		if err := rows.Scan(&id, &sum); err != nil {
+			return nil, fmt.Errorf("failed to scan migration checksum: %w", err)
+		}
+		result[id] = sum
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get migration checksums: %w", err)
+	}
+
+	return result, nil
+}
+
+// createChecksumsTable creates the checksums table if it doesn't exist.
+func (m *Migrator) createChecksumsTable() error {
+	if _, err := m.db.Exec(`CREATE TABLE IF NOT EXISTS ` + m.checksumsTable() + ` (
+		id TEXT PRIMARY KEY,
+		checksum TEXT NOT NULL,
+		recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+	)`); err != nil {
+		return fmt.Errorf("failed to create migration checksums table: %w", err)
+	}
+	return nil
+}
+
+// syncChecksums records checksums of applied migrations which don't have one yet
+// and removes checksums of reverted migrations.
+// Existing checksums are never overwritten, so changed migrations keep failing Verify
+// until they are reverted and applied again.
+func (m *Migrator) syncChecksums() error {
+	migrations, err := m.source.FindMigrations()
+	if err != nil {
+		return fmt.Errorf("failed to find migrations: %w", err)
+	}
+	records, err := m.set.GetMigrationRecords(m.db, dialect)
+	if err != nil {
+		return fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	checksums, err := m.checksums()
+	if err != nil {
+		return err
+	}
+
+	applied := make(map[string]bool, len(records))
+	for _, r := range records {
+		applied[r.Id] = true
+	}
+
+	for id := range checksums {
+		if applied[id] {
+			continue
+		}
+		if _, err := m.db.Exec("DELETE FROM "+m.checksumsTable()+" WHERE id = $1", id); err != nil {
+			return fmt.Errorf("failed to delete checksum of migration %s: %w", id, err)
+		}
+	}
+
+	for _, mg := range migrations {
+		if _, ok := checksums[mg.Id]; ok || !applied[mg.Id] {
+			continue
+		}
+		if _, err := m.db.Exec(
+			"INSERT INTO "+m.checksumsTable()+" (id, checksum) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING",
+			mg.Id, Checksum(mg),
+		); err != nil {
+			return fmt.Errorf("failed to record checksum of migration %s: %w", mg.Id, err)
+		}
+	}
+
+	return nil
+}
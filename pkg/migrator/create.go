@@ -0,0 +1,52 @@
+package migrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// migrationTemplate is the content of a new migration file.
+const migrationTemplate = `-- +migrate Up
+-- +migrate StatementBegin
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+
+-- +migrate StatementEnd
+`
+
+// nameCleaner matches characters not allowed in migration names.
+var nameCleaner = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Create creates a new migration file in dir named after the time and the name,
+// e.g. "20261019090000-create_users_table.sql", with Up and Down sections.
+// The directory is created if it doesn't exist. It returns the path to the file.
+func Create(dir, name string, now time.Time) (string, error) {
+	name = strings.Trim(nameCleaner.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "", ErrInvalidName
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create migrations directory: %w", err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.sql", now.UTC().Format("20060102150405"), name))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("failed to create migration file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(migrationTemplate); err != nil {
+		return "", fmt.Errorf("failed to write migration file: %w", err)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,15 @@
+package migrator
+
+import "errors"
+
+// Predefined errors.
+var (
+	ErrDuplicateMigration = errors.New("duplicate migration id")
+	ErrChecksumMismatch   = errors.New("applied migration differs from the source")
+	ErrMissingMigration   = errors.New("applied migration is missing in the source")
+	ErrInvalidAnnotation  = errors.New("invalid migration annotation")
+	ErrInvalidName        = errors.New("migration name must contain letters or digits")
+	ErrLockTimeout        = errors.New("timed out waiting for the migrations lock")
+	ErrNothingToRedo      = errors.New("no applied migrations to redo")
+	ErrUnknownVersion     = errors.New("unknown migration version")
+)
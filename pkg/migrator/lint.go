@@ -0,0 +1,222 @@
+package migrator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Lint rules.
+const (
+	RuleNotNullWithoutDefault = "not-null-without-default"
+	RuleSetNotNull            = "set-not-null"
+	RuleNonConcurrentIndex    = "non-concurrent-index"
+	RuleConcurrentInTx        = "concurrent-index-in-transaction"
+	RuleColumnTypeChange      = "column-type-change"
+	RuleRename                = "rename"
+	RuleMissingDown           = "missing-down"
+	RuleMissingLockTimeout    = "missing-lock-timeout"
+)
+
+// previewLength is the max length of the statement shown in the issue.
+const previewLength = 80
+
+// Issue is a risky operation found in a migration.
+type Issue struct {
+	Migration string
+	Rule      string
+	Message   string
+	Statement string
+}
+
+// String returns the issue in the "migration: rule: message" format.
+func (i Issue) String() string {
+	s := fmt.Sprintf("%s: %s: %s", i.Migration, i.Rule, i.Message)
+	if i.Statement != "" {
+		s += "\n    " + i.Statement
+	}
+	return s
+}
+
+var (
+	lintComments     = regexp.MustCompile(`(?s)--[^\n]*|/\*.*?\*/`)
+	lintDollarQuoted = regexp.MustCompile(`(?s)\$[A-Za-z_]*\$.*?\$[A-Za-z_]*\$`)
+	lintStrings      = regexp.MustCompile(`'(?:[^']|'')*'`)
+	lintSpaces       = regexp.MustCompile(`\s+`)
+
+	lintCreateTable = regexp.MustCompile(`^CREATE (?:UNLOGGED |TEMP |TEMPORARY )?TABLE (?:IF NOT EXISTS )?([^\s(]+)`)
+	lintAlterTable  = regexp.MustCompile(`^ALTER TABLE (?:IF EXISTS )?(?:ONLY )?([^\s]+) (.*)$`)
+	lintCreateIndex = regexp.MustCompile(`^CREATE (?:UNIQUE )?INDEX (CONCURRENTLY )?(?:.*? )?ON (?:ONLY )?([^\s(]+)`)
+	lintAddColumn   = regexp.MustCompile(`^ADD (?:COLUMN )?`)
+	lintAlterType   = regexp.MustCompile(`^ALTER (?:COLUMN )?\S+ (?:SET DATA )?TYPE `)
+	lintSetNotNull  = regexp.MustCompile(`^ALTER (?:COLUMN )?\S+ SET NOT NULL`)
+	lintRename      = regexp.MustCompile(`^RENAME `)
+	lintLockTimeout = regexp.MustCompile(`^SET (?:LOCAL )?LOCK_TIMEOUT`)
+	lintNotNull     = regexp.MustCompile(`\bNOT NULL\b`)
+	lintHasDefault  = regexp.MustCompile(`\bDEFAULT\b|\bGENERATED\b`)
+)
+
+// Lint checks the Up statements of the migration for operations taking long
+// ACCESS EXCLUSIVE locks on existing tables, and checks that the migration can be reverted.
+// Tables created by the same migration are empty, so operations on them are not reported.
+func Lint(m *migrate.Migration) []Issue {
+	var issues []Issue
+	report := func(rule, stmt, format string, args ...interface{}) {
+		issues = append(issues, Issue{
+			Migration: m.Id,
+			Rule:      rule,
+			Message:   fmt.Sprintf(format, args...),
+			Statement: preview(stmt),
+		})
+	}
+
+	created := make(map[string]bool)
+	var hasLockTimeout, altersExisting bool
+
+	for _, q := range m.Up {
+		stmt := normalizeStatement(q)
+		if stmt == "" {
+			continue
+		}
+
+		if lintLockTimeout.MatchString(stmt) {
+			hasLockTimeout = true
+			continue
+		}
+
+		if match := lintCreateTable.FindStringSubmatch(stmt); match != nil {
+			created[tableName(match[1])] = true
+			continue
+		}
+
+		if match := lintCreateIndex.FindStringSubmatch(stmt); match != nil {
+			concurrent := match[1] != ""
+			switch {
+			case concurrent && !m.DisableTransactionUp:
+				report(RuleConcurrentInTx, q, "CREATE INDEX CONCURRENTLY can't run in a transaction, use '-- +migrate Up notransaction'")
+			case !concurrent && !created[tableName(match[2])]:
+				altersExisting = true
+				report(RuleNonConcurrentIndex, q, "creating an index blocks writes to %s, use CREATE INDEX CONCURRENTLY", tableName(match[2]))
+			}
+			continue
+		}
+
+		if strings.HasPrefix(stmt, "ALTER INDEX ") && strings.Contains(stmt, " RENAME ") {
+			report(RuleRename, q, "renaming breaks the code running against the old name during deploy")
+			continue
+		}
+
+		match := lintAlterTable.FindStringSubmatch(stmt)
+		if match == nil {
+			continue
+		}
+		table := tableName(match[1])
+		if created[table] {
+			continue
+		}
+		altersExisting = true
+
+		for _, action := range splitActions(match[2]) {
+			switch {
+			case lintAddColumn.MatchString(action) && !strings.HasPrefix(action, "ADD CONSTRAINT"):
+				if lintNotNull.MatchString(action) && !lintHasDefault.MatchString(action) {
+					report(RuleNotNullWithoutDefault, q, "adding a NOT NULL column without a default fails on a non-empty %s", table)
+				}
+			case lintSetNotNull.MatchString(action):
+				report(RuleSetNotNull, q, "SET NOT NULL scans %s holding an ACCESS EXCLUSIVE lock, add a NOT VALID check constraint and validate it first", table)
+			case lintAlterType.MatchString(action):
+				report(RuleColumnTypeChange, q, "changing a column type may rewrite %s holding an ACCESS EXCLUSIVE lock", table)
+			case lintRename.MatchString(action):
+				report(RuleRename, q, "renaming breaks the code running against the old name during deploy")
+			}
+		}
+	}
+
+	if altersExisting && !hasLockTimeout {
+		// The annotation is rejected without a transaction, SET LOCAL has no effect there
+		if m.DisableTransactionUp {
+			report(RuleMissingLockTimeout, "", "the migration locks existing tables, add \"SET lock_timeout = '5s';\" before the locking statements so it doesn't queue behind long transactions")
+		} else {
+			report(RuleMissingLockTimeout, "", "the migration locks existing tables, set '-- +lock_timeout' so it doesn't queue behind long transactions")
+		}
+	}
+
+	if len(m.Down) == 0 || isBlank(m.Down) {
+		report(RuleMissingDown, "", "the migration has no Down statements and can't be reverted")
+	}
+
+	return issues
+}
+
+// Lint checks the pending migrations, see Lint for the rules.
+func (m *Migrator) Lint() ([]Issue, error) {
+	plan, _, err := m.set.PlanMigration(m.db, dialect, m.source, migrate.Up, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan migrations: %w", err)
+	}
+
+	var issues []Issue
+	for _, p := range plan {
+		issues = append(issues, Lint(p.Migration)...)
+	}
+	return issues, nil
+}
+
+// normalizeStatement removes comments and literals from the statement,
+// collapses whitespace and converts it to upper case.
+func normalizeStatement(q string) string {
+	q = lintDollarQuoted.ReplaceAllString(q, "$$$$")
+	q = lintStrings.ReplaceAllString(q, "''")
+	q = lintComments.ReplaceAllString(q, " ")
+	q = lintSpaces.ReplaceAllString(q, " ")
+	return strings.ToUpper(strings.TrimSuffix(strings.TrimSpace(q), ";"))
+}
+
+// splitActions splits the actions of ALTER TABLE by commas outside of parentheses.
+func splitActions(s string) []string {
+	var result []string
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				result = append(result, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(result, strings.TrimSpace(s[start:]))
+}
+
+// tableName returns the table name without the schema and quotes.
+func tableName(s string) string {
+	if i := strings.LastIndex(s, "."); i >= 0 {
+		s = s[i+1:]
+	}
+	return strings.Trim(s, `"`)
+}
+
+// isBlank reports whether all statements are empty.
+func isBlank(queries []string) bool {
+	for _, q := range queries {
+		if normalizeStatement(q) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// preview returns the statement collapsed to a single line of limited length.
+func preview(q string) string {
+	q = lintSpaces.ReplaceAllString(strings.TrimSpace(q), " ")
+	if len(q) > previewLength {
+		q = q[:previewLength] + "..."
+	}
+	return q
+}
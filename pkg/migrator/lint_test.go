@@ -0,0 +1,141 @@
+package migrator_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/dmitrymomot/go-app/pkg/migrator"
+	migrate "github.com/rubenv/sql-migrate"
+	"github.com/stretchr/testify/require"
+)
+
+// rules returns the rules of the issues.
+func rules(issues []migrator.Issue) []string {
+	result := make([]string, 0, len(issues))
+	for _, i := range issues {
+		result = append(result, i.Rule)
+	}
+	return result
+}
+
+func TestLint(t *testing.T) {
+	down := []string{"SELECT 1;"}
+
+	// Test case 1: risky operations on existing tables are reported
+	t.Run("risky operations are reported", func(t *testing.T) {
+		issues := migrator.Lint(&migrate.Migration{
+			Id: "1-users.sql",
+			Up: []string{
+				"ALTER TABLE users ADD COLUMN email TEXT NOT NULL;",
+				"ALTER TABLE users ADD COLUMN active BOOLEAN NOT NULL DEFAULT TRUE, ALTER COLUMN name TYPE TEXT;",
+				"ALTER TABLE public.users ALTER COLUMN email SET NOT NULL;",
+				"CREATE INDEX users_email_idx ON users (email);",
+				"ALTER TABLE users RENAME COLUMN name TO full_name;",
+				"ALTER INDEX users_email_idx RENAME TO users_email_key;",
+			},
+		})
+		require.Equal(t, []string{
+			migrator.RuleNotNullWithoutDefault,
+			migrator.RuleColumnTypeChange,
+			migrator.RuleSetNotNull,
+			migrator.RuleNonConcurrentIndex,
+			migrator.RuleRename,
+			migrator.RuleRename,
+			migrator.RuleMissingLockTimeout,
+			migrator.RuleMissingDown,
+		}, rules(issues))
+		require.Equal(t, "1-users.sql", issues[0].Migration)
+		require.Equal(t, "ALTER TABLE users ADD COLUMN email TEXT NOT NULL;", issues[0].Statement)
+	})
+
+	// Test case 2: operations on tables created by the migration are safe
+	t.Run("new tables are safe", func(t *testing.T) {
+		issues := migrator.Lint(&migrate.Migration{
+			Id: "1-users.sql",
+			Up: []string{
+				"CREATE TABLE IF NOT EXISTS users (id INT);",
+				"ALTER TABLE users ADD COLUMN email TEXT NOT NULL;",
+				"CREATE UNIQUE INDEX ON users (email);",
+				"-- a comment: ALTER TABLE users RENAME TO people;\nSELECT 'ALTER TABLE posts RENAME TO articles';",
+			},
+			Down: down,
+		})
+		require.Empty(t, issues)
+	})
+
+	// Test case 3: concurrent index creation must be outside of a transaction
+	t.Run("concurrent index creation", func(t *testing.T) {
+		m := &migrate.Migration{
+			Id:   "1-users.sql",
+			Up:   []string{"SET LOCAL lock_timeout = '5000ms';", "CREATE INDEX CONCURRENTLY IF NOT EXISTS users_email_idx ON users (email);"},
+			Down: down,
+		}
+		require.Equal(t, []string{migrator.RuleConcurrentInTx}, rules(migrator.Lint(m)))
+
+		m.DisableTransactionUp = true
+		require.Empty(t, migrator.Lint(m))
+	})
+
+	// Test case 4: lock timeout advice of a migration without a transaction
+	t.Run("lock timeout without transaction", func(t *testing.T) {
+		m := &migrate.Migration{
+			Id:                   "1-users.sql",
+			Up:                   []string{"ALTER TABLE users RENAME COLUMN name TO full_name;"},
+			Down:                 down,
+			DisableTransactionUp: true,
+		}
+		issues := migrator.Lint(m)
+		require.Equal(t, []string{migrator.RuleRename, migrator.RuleMissingLockTimeout}, rules(issues))
+		require.Contains(t, issues[1].Message, "SET lock_timeout = '5s';")
+		require.NotContains(t, issues[1].Message, "-- +lock_timeout")
+
+		m.Up = append([]string{"SET lock_timeout = '5s';"}, m.Up...)
+		require.Equal(t, []string{migrator.RuleRename}, rules(migrator.Lint(m)))
+	})
+
+	// Test case 5: function bodies are ignored
+	t.Run("function bodies are ignored", func(t *testing.T) {
+		issues := migrator.Lint(&migrate.Migration{
+			Id:   "1-fn.sql",
+			Up:   []string{"CREATE OR REPLACE FUNCTION f() RETURNS VOID AS $$ BEGIN ALTER TABLE users RENAME TO people; END; $$ LANGUAGE plpgsql;"},
+			Down: down,
+		})
+		require.Empty(t, issues)
+	})
+}
+
+func TestFSSource(t *testing.T) {
+	// Test case 1: timeouts are set in both directions
+	t.Run("timeouts are set", func(t *testing.T) {
+		src := migrator.FSSource{FS: fstest.MapFS{
+			"migrations/2-email.sql": {Data: []byte("-- +migrate Up\n-- +lock_timeout 5s\n-- +statement_timeout 1m\nALTER TABLE users ADD COLUMN email TEXT;\n\n-- +migrate Down\nALTER TABLE users DROP COLUMN email;\n")},
+			"migrations/1-users.sql": {Data: []byte("-- +migrate Up\nCREATE TABLE users (id INT);\n")},
+			"migrations/README.md":   {Data: []byte("# Migrations")},
+		}, Root: "migrations"}
+
+		migrations, err := src.FindMigrations()
+		require.NoError(t, err)
+		require.Len(t, migrations, 2)
+		require.Equal(t, "1-users.sql", migrations[0].Id)
+		require.Equal(t, []string{"CREATE TABLE users (id INT);\n"}, migrations[0].Up)
+
+		require.Equal(t, []string{
+			"SET LOCAL lock_timeout = '5000ms';",
+			"SET LOCAL statement_timeout = '60000ms';",
+			"ALTER TABLE users ADD COLUMN email TEXT;\n",
+		}, migrations[1].Up)
+		require.Len(t, migrations[1].Down, 3)
+		require.Empty(t, migrator.Lint(migrations[1]))
+	})
+
+	// Test case 2: invalid annotations are rejected
+	t.Run("invalid annotations are rejected", func(t *testing.T) {
+		for _, content := range []string{
+			"-- +migrate Up\n-- +lock_timeout soon\nSELECT 1;\n",
+			"-- +migrate Up notransaction\n-- +lock_timeout 5s\nSELECT 1;\n",
+		} {
+			_, err := migrator.FSSource{FS: fstest.MapFS{"1-users.sql": {Data: []byte(content)}}}.FindMigrations()
+			require.ErrorIs(t, err, migrator.ErrInvalidAnnotation)
+		}
+	})
+}
@@ -0,0 +1,394 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/dmitrymomot/go-app/pkg/pglock"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+const dialect = "postgres"
+
+type (
+	// Migrator applies and reverts sql-migrate migrations from the given source.
+	Migrator struct {
+		db     *sql.DB
+		source migrate.MigrationSource
+		set    migrate.MigrationSet
+		dryRun io.Writer
+
+		locker      pglock.Locker
+		lockTimeout time.Duration
+		verify      bool
+	}
+
+	// Option is a function that configures the Migrator.
+	Option func(*Migrator)
+
+	// Status is the state of a single migration.
+	Status struct {
+		ID        string
+		Applied   bool
+		AppliedAt time.Time
+		// Missing is true for migrations recorded in the database
+		// but not found in the migration source.
+		Missing bool
+	}
+)
+
+// New returns a new Migrator instance.
+func New(db *sql.DB, source migrate.MigrationSource, opts ...Option) *Migrator {
+	m := &Migrator{
+		db:     db,
+		source: source,
+		set:    migrate.MigrationSet{TableName: "migrations"},
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// WithTableName sets the name of the table used to track applied migrations.
+// Default is "migrations".
+func WithTableName(name string) Option {
+	return func(m *Migrator) {
+		if name != "" {
+			m.set.TableName = name
+		}
+	}
+}
+
+// WithDryRun makes the Migrator write the planned SQL to w instead of executing it.
+// The migrations table is still created if it does not exist.
+func WithDryRun(w io.Writer) Option {
+	return func(m *Migrator) {
+		m.dryRun = w
+	}
+}
+
+// WithLock makes the Migrator apply and revert migrations holding the Postgres
+// advisory lock, so several instances started at once don't race on the same migrations.
+// The lock is awaited at most timeout, ErrLockTimeout is returned after that.
+// Instances waiting for the lock find the migrations already applied and do nothing.
+func WithLock(locker pglock.Locker, timeout time.Duration) Option {
+	return func(m *Migrator) {
+		m.locker = locker
+		m.lockTimeout = timeout
+		if m.lockTimeout <= 0 {
+			m.lockTimeout = time.Minute
+		}
+	}
+}
+
+// WithVerify makes Up fail before applying migrations if Verify fails.
+func WithVerify() Option {
+	return func(m *Migrator) {
+		m.verify = true
+	}
+}
+
+// Up applies at most n pending migrations, all of them if n <= 0.
+// It returns the number of applied migrations.
+func (m *Migrator) Up(n int) (applied int, err error) {
+	err = m.exec(func() error {
+		if m.verify {
+			if err := m.Verify(); err != nil {
+				return err
+			}
+		}
+		applied, err = m.up(n)
+		return err
+	})
+	return applied, err
+}
+
+// Down reverts at most n applied migrations, all of them if n <= 0.
+// It returns the number of reverted migrations.
+func (m *Migrator) Down(n int) (reverted int, err error) {
+	err = m.exec(func() error {
+		reverted, err = m.down(n)
+		return err
+	})
+	return reverted, err
+}
+
+// Redo reverts the last applied migration and applies it again.
+func (m *Migrator) Redo() error {
+	return m.exec(m.redo)
+}
+
+// To migrates the database up or down so that the migration with the given
+// version is the last applied one. Version 0 reverts all migrations.
+// It returns the direction and the number of applied or reverted migrations.
+func (m *Migrator) To(version int64) (dir migrate.MigrationDirection, n int, err error) {
+	err = m.exec(func() error {
+		dir, n, err = m.to(version)
+		return err
+	})
+	return dir, n, err
+}
+
+// exec runs fn changing the database and records checksums of applied migrations,
+// even if fn failed halfway.
+func (m *Migrator) exec(fn func() error) error {
+	return m.locked(func() error {
+		err := fn()
+		if m.dryRun != nil {
+			return err
+		}
+		if serr := m.syncChecksums(); serr != nil && err == nil {
+			err = serr
+		}
+		return err
+	})
+}
+
+// locked runs fn holding the migrations lock if it is configured.
+// Dry runs don't change the database, so they don't wait for the lock.
+func (m *Migrator) locked(fn func() error) error {
+	if m.locker == nil || m.dryRun != nil {
+		return fn()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.lockTimeout)
+	defer cancel()
+
+	lock, err := m.locker.Lock(ctx, "migrator:"+m.set.TableName)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("%w: %s", ErrLockTimeout, m.lockTimeout)
+		}
+		return err
+	}
+	defer lock.Unlock(context.Background()) // nolint:errcheck
+
+	return fn()
+}
+
+// up applies at most n pending migrations.
+func (m *Migrator) up(n int) (int, error) {
+	if n < 0 {
+		n = 0
+	}
+	if m.dryRun != nil {
+		plan, _, err := m.set.PlanMigration(m.db, dialect, m.source, migrate.Up, n)
+		if err != nil {
+			return 0, fmt.Errorf("failed to plan migrations: %w", err)
+		}
+		return len(plan), WritePlan(m.dryRun, migrate.Up, plan)
+	}
+
+	applied, err := m.set.ExecMax(m.db, dialect, m.source, migrate.Up, n)
+	if err != nil {
+		return applied, fmt.Errorf("failed to apply migrations: %w", err)
+	}
+	return applied, nil
+}
+
+// down reverts at most n applied migrations.
+func (m *Migrator) down(n int) (int, error) {
+	if n < 0 {
+		n = 0
+	}
+	if m.dryRun != nil {
+		plan, _, err := m.set.PlanMigration(m.db, dialect, m.source, migrate.Down, n)
+		if err != nil {
+			return 0, fmt.Errorf("failed to plan migrations: %w", err)
+		}
+		return len(plan), WritePlan(m.dryRun, migrate.Down, plan)
+	}
+
+	reverted, err := m.set.ExecMax(m.db, dialect, m.source, migrate.Down, n)
+	if err != nil {
+		return reverted, fmt.Errorf("failed to revert migrations: %w", err)
+	}
+	return reverted, nil
+}
+
+// redo reverts the last applied migration and applies it again.
+func (m *Migrator) redo() error {
+	last, err := m.lastApplied()
+	if err != nil {
+		return err
+	}
+	if last == nil {
+		return ErrNothingToRedo
+	}
+
+	if m.dryRun != nil {
+		migration, err := m.find(last.Id)
+		if err != nil {
+			return err
+		}
+		if err := WritePlan(m.dryRun, migrate.Down, []*migrate.PlannedMigration{{
+			Migration: migration,
+			Queries:   migration.Down,
+		}}); err != nil {
+			return err
+		}
+		return WritePlan(m.dryRun, migrate.Up, []*migrate.PlannedMigration{{
+			Migration: migration,
+			Queries:   migration.Up,
+		}})
+	}
+
+	if _, err := m.set.ExecMax(m.db, dialect, m.source, migrate.Down, 1); err != nil {
+		return fmt.Errorf("failed to revert migration %s: %w", last.Id, err)
+	}
+	// Forget the checksum of the reverted migration, it may be applied changed.
+	if err := m.syncChecksums(); err != nil {
+		return err
+	}
+	version, err := ParseVersion(last.Id)
+	if err != nil {
+		return err
+	}
+	if _, err := m.set.ExecVersion(m.db, dialect, m.source, migrate.Up, version); err != nil {
+		return fmt.Errorf("failed to apply migration %s: %w", last.Id, err)
+	}
+	return nil
+}
+
+// to migrates the database up or down to the given version.
+func (m *Migrator) to(version int64) (migrate.MigrationDirection, int, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return migrate.Up, 0, err
+	}
+
+	var known bool
+	var current int64
+	var newer int
+	for _, s := range statuses {
+		v, _ := versionOf(s.ID)
+		if v == version {
+			known = true
+		}
+		if !s.Applied {
+			continue
+		}
+		if v > current {
+			current = v
+		}
+		if v > version {
+			newer++
+		}
+	}
+	if version != 0 && !known {
+		return migrate.Up, 0, fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	if version > current {
+		if m.dryRun != nil {
+			plan, _, err := m.set.PlanMigrationToVersion(m.db, dialect, m.source, migrate.Up, version)
+			if err != nil {
+				return migrate.Up, 0, fmt.Errorf("failed to plan migrations: %w", err)
+			}
+			return migrate.Up, len(plan), WritePlan(m.dryRun, migrate.Up, plan)
+		}
+		applied, err := m.set.ExecVersion(m.db, dialect, m.source, migrate.Up, version)
+		if err != nil {
+			return migrate.Up, applied, fmt.Errorf("failed to apply migrations: %w", err)
+		}
+		return migrate.Up, applied, nil
+	}
+
+	if newer == 0 {
+		return migrate.Down, 0, nil
+	}
+	reverted, err := m.down(newer)
+	return migrate.Down, reverted, err
+}
+
+// Status returns the state of all known migrations ordered by id.
+// Migrations applied to the database but missing in the source are included too.
+func (m *Migrator) Status() ([]Status, error) {
+	migrations, err := m.source.FindMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find migrations: %w", err)
+	}
+
+	records, err := m.set.GetMigrationRecords(m.db, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	applied := make(map[string]time.Time, len(records))
+	for _, r := range records {
+		applied[r.Id] = r.AppliedAt
+	}
+
+	result := make([]Status, 0, len(migrations)+len(records))
+	for _, mg := range migrations {
+		at, ok := applied[mg.Id]
+		result = append(result, Status{ID: mg.Id, Applied: ok, AppliedAt: at})
+		delete(applied, mg.Id)
+	}
+	for id, at := range applied {
+		result = append(result, Status{ID: id, Applied: true, AppliedAt: at, Missing: true})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return (&migrate.Migration{Id: result[i].ID}).Less(&migrate.Migration{Id: result[j].ID})
+	})
+
+	return result, nil
+}
+
+// lastApplied returns the record of the last applied migration or nil if there is none.
+func (m *Migrator) lastApplied() (*migrate.MigrationRecord, error) {
+	records, err := m.set.GetMigrationRecords(m.db, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	var last *migrate.MigrationRecord
+	for _, r := range records {
+		if last == nil || (&migrate.Migration{Id: last.Id}).Less(&migrate.Migration{Id: r.Id}) {
+			last = r
+		}
+	}
+	return last, nil
+}
+
+// find returns the migration with the given id from the source.
+func (m *Migrator) find(id string) (*migrate.Migration, error) {
+	migrations, err := m.source.FindMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find migrations: %w", err)
+	}
+	for _, mg := range migrations {
+		if mg.Id == id {
+			return mg, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownVersion, id)
+}
+
+// ParseVersion parses the migration version from a migration id or a plain number,
+// e.g. "20261019090000-scheduler_jobs.sql" or "20261019090000".
+func ParseVersion(s string) (int64, error) {
+	v, ok := versionOf(s)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownVersion, s)
+	}
+	return v, nil
+}
+
+// versionOf returns the numeric prefix of the migration id.
+func versionOf(id string) (int64, bool) {
+	m := migrate.Migration{Id: id}
+	if len(m.NumberPrefixMatches()) == 0 {
+		return 0, false
+	}
+	return m.VersionInt(), true
+}
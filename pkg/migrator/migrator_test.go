@@ -0,0 +1,208 @@
+package migrator_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dmitrymomot/go-app/pkg/migrator"
+	"github.com/dmitrymomot/go-app/pkg/pglock"
+	migrate "github.com/rubenv/sql-migrate"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	// Test case 1: version from migration id
+	t.Run("version from migration id", func(t *testing.T) {
+		v, err := migrator.ParseVersion("20261019090000-scheduler_jobs.sql")
+		require.NoError(t, err)
+		require.EqualValues(t, 20261019090000, v)
+	})
+
+	// Test case 2: plain version
+	t.Run("plain version", func(t *testing.T) {
+		v, err := migrator.ParseVersion("20261019100000")
+		require.NoError(t, err)
+		require.EqualValues(t, 20261019100000, v)
+	})
+
+	// Test case 3: invalid version
+	t.Run("invalid version", func(t *testing.T) {
+		_, err := migrator.ParseVersion("latest")
+		require.ErrorIs(t, err, migrator.ErrUnknownVersion)
+	})
+}
+
+func TestWritePlan(t *testing.T) {
+	plan := []*migrate.PlannedMigration{
+		{
+			Migration: &migrate.Migration{Id: "1-users.sql"},
+			Queries:   []string{"CREATE TABLE users (id INT);\n", "  "},
+		},
+		{
+			Migration: &migrate.Migration{Id: "2-posts.sql"},
+			Queries:   []string{"CREATE TABLE posts (id INT);"},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, migrator.WritePlan(&buf, migrate.Up, plan))
+	require.Equal(t, "-- 1-users.sql (up)\nCREATE TABLE users (id INT);\n\n-- 2-posts.sql (up)\nCREATE TABLE posts (id INT);\n\n", buf.String())
+}
+
+func TestWriteStatus(t *testing.T) {
+	at := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	require.NoError(t, migrator.WriteStatus(&buf, []migrator.Status{
+		{ID: "1-users.sql", Applied: true, AppliedAt: at},
+		{ID: "2-removed.sql", Applied: true, AppliedAt: at, Missing: true},
+		{ID: "3-posts.sql"},
+	}))
+
+	require.Equal(t, ""+
+		"MIGRATION      STATUS   APPLIED AT\n"+
+		"1-users.sql    applied  2026-10-19T09:00:00Z\n"+
+		"2-removed.sql  missing  2026-10-19T09:00:00Z\n"+
+		"3-posts.sql    pending  -\n", buf.String())
+}
+
+func TestMerge(t *testing.T) {
+	auth := &migrate.MemoryMigrationSource{Migrations: []*migrate.Migration{
+		{Id: "20230102000000-users.sql"},
+		{Id: "20230104000000-tokens.sql"},
+	}}
+	billing := &migrate.MemoryMigrationSource{Migrations: []*migrate.Migration{
+		{Id: "20230103000000-plans.sql"},
+	}}
+	system := &migrate.MemoryMigrationSource{Migrations: []*migrate.Migration{
+		{Id: "00000000000000-db_preparing.sql"},
+	}}
+
+	// Test case 1: migrations are ordered by id regardless of the order of sources
+	t.Run("migrations are ordered by id", func(t *testing.T) {
+		for _, src := range []migrate.MigrationSource{
+			migrator.Merge(system, auth, billing),
+			migrator.Merge(billing, auth, system),
+		} {
+			migrations, err := src.FindMigrations()
+			require.NoError(t, err)
+
+			ids := make([]string, 0, len(migrations))
+			for _, m := range migrations {
+				ids = append(ids, m.Id)
+			}
+			require.Equal(t, []string{
+				"00000000000000-db_preparing.sql",
+				"20230102000000-users.sql",
+				"20230103000000-plans.sql",
+				"20230104000000-tokens.sql",
+			}, ids)
+		}
+	})
+
+	// Test case 2: duplicate ids are rejected
+	t.Run("duplicate ids are rejected", func(t *testing.T) {
+		_, err := migrator.Merge(auth, billing, auth).FindMigrations()
+		require.ErrorIs(t, err, migrator.ErrDuplicateMigration)
+	})
+}
+
+// busyLocker is a pglock.Locker whose lock is always held by another instance.
+type busyLocker struct{}
+
+func (busyLocker) TryLock(context.Context, string) (pglock.Lock, error) {
+	return nil, pglock.ErrNotAcquired
+}
+
+func (busyLocker) Lock(ctx context.Context, key string) (pglock.Lock, error) {
+	<-ctx.Done()
+	return nil, fmt.Errorf("failed to acquire advisory lock %q: %w", key, ctx.Err())
+}
+
+func TestWithLock(t *testing.T) {
+	m := migrator.New(nil, &migrate.MemoryMigrationSource{},
+		migrator.WithLock(busyLocker{}, 10*time.Millisecond),
+	)
+
+	_, err := m.Up(0)
+	require.ErrorIs(t, err, migrator.ErrLockTimeout)
+
+	_, err = m.Down(1)
+	require.ErrorIs(t, err, migrator.ErrLockTimeout)
+
+	require.ErrorIs(t, m.Redo(), migrator.ErrLockTimeout)
+
+	_, _, err = m.To(0)
+	require.ErrorIs(t, err, migrator.ErrLockTimeout)
+}
+
+func TestChecksum(t *testing.T) {
+	m := &migrate.Migration{
+		Id:   "1-users.sql",
+		Up:   []string{"CREATE TABLE users (id INT);\n"},
+		Down: []string{"DROP TABLE users;\n"},
+	}
+
+	// Test case 1: formatting around statements doesn't change the checksum
+	t.Run("formatting around statements is ignored", func(t *testing.T) {
+		formatted := *m
+		formatted.Up = []string{"\n  CREATE TABLE users (id INT);  \n"}
+		require.Equal(t, migrator.Checksum(m), migrator.Checksum(&formatted))
+	})
+
+	// Test case 2: changed statements change the checksum
+	t.Run("changed statements change the checksum", func(t *testing.T) {
+		changed := *m
+		changed.Up = []string{"CREATE TABLE users (id BIGINT);"}
+		require.NotEqual(t, migrator.Checksum(m), migrator.Checksum(&changed))
+
+		changed = *m
+		changed.Down = nil
+		require.NotEqual(t, migrator.Checksum(m), migrator.Checksum(&changed))
+
+		changed = *m
+		changed.DisableTransactionUp = true
+		require.NotEqual(t, migrator.Checksum(m), migrator.Checksum(&changed))
+	})
+
+	// Test case 3: verify error matches the problems
+	t.Run("verify error matches the problems", func(t *testing.T) {
+		err := error(migrator.VerifyError{
+			fmt.Errorf("%w: 1-users.sql", migrator.ErrChecksumMismatch),
+		})
+		require.ErrorIs(t, err, migrator.ErrChecksumMismatch)
+		require.NotErrorIs(t, err, migrator.ErrMissingMigration)
+	})
+}
+
+func TestCreate(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)
+
+	// Test case 1: migration file is created with the timestamp and the cleaned name
+	t.Run("migration file is created", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "auth", "repository", "sql", "migrations")
+
+		path, err := migrator.Create(dir, "Create Users-table", now)
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dir, "20261019093000-create_users_table.sql"), path)
+
+		src := &migrate.FileMigrationSource{Dir: dir}
+		migrations, err := src.FindMigrations()
+		require.NoError(t, err)
+		require.Len(t, migrations, 1)
+		require.EqualValues(t, 20261019093000, migrations[0].VersionInt())
+
+		_, err = migrator.Create(dir, "create_users_table", now)
+		require.Error(t, err)
+	})
+
+	// Test case 2: invalid name
+	t.Run("invalid name", func(t *testing.T) {
+		_, err := migrator.Create(t.TempDir(), " -- ", now)
+		require.ErrorIs(t, err, migrator.ErrInvalidName)
+	})
+}
@@ -0,0 +1,147 @@
+package migrator
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// mergedSource is the migration source combining several sources.
+type mergedSource []migrate.MigrationSource
+
+// Merge returns the migration source with the migrations of all given sources.
+// Migrations are ordered by id regardless of the order of sources,
+// the same migration id in several sources is an error.
+func Merge(sources ...migrate.MigrationSource) migrate.MigrationSource {
+	return mergedSource(sources)
+}
+
+// FindMigrations implements migrate.MigrationSource.
+func (s mergedSource) FindMigrations() ([]*migrate.Migration, error) {
+	var result []*migrate.Migration
+	seen := make(map[string]bool)
+
+	for _, src := range s {
+		migrations, err := src.FindMigrations()
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range migrations {
+			if seen[m.Id] {
+				return nil, fmt.Errorf("%w: %s", ErrDuplicateMigration, m.Id)
+			}
+			seen[m.Id] = true
+			result = append(result, m)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Less(result[j])
+	})
+
+	return result, nil
+}
+
+// FSSource is the migration source reading *.sql files from the directory
+// of the file system, e.g. embed.FS or os.DirFS.
+//
+// Besides the sql-migrate annotations, migrations may set Postgres timeouts
+// for both directions, they are applied with SET LOCAL in the migration transaction:
+//
+//	-- +lock_timeout 5s
+//	-- +statement_timeout 1m
+type FSSource struct {
+	FS   fs.FS
+	Root string
+}
+
+// FindMigrations implements migrate.MigrationSource.
+func (s FSSource) FindMigrations() ([]*migrate.Migration, error) {
+	root := s.Root
+	if root == "" {
+		root = "."
+	}
+
+	entries, err := fs.ReadDir(s.FS, root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory %s: %w", root, err)
+	}
+
+	var result []*migrate.Migration
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+
+		content, err := fs.ReadFile(s.FS, path.Join(root, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", e.Name(), err)
+		}
+		m, err := parseMigration(e.Name(), content)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, m)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Less(result[j])
+	})
+
+	return result, nil
+}
+
+// timeoutAnnotations maps migration annotations to Postgres settings.
+var timeoutAnnotations = map[string]string{
+	"-- +lock_timeout":      "lock_timeout",
+	"-- +statement_timeout": "statement_timeout",
+}
+
+// parseMigration parses the migration file and prepends the annotated timeouts
+// to the statements of both directions.
+func parseMigration(id string, content []byte) (*migrate.Migration, error) {
+	m, err := migrate.ParseMigration(id, bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse migration %s: %w", id, err)
+	}
+
+	var settings []string
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		name, ok := timeoutAnnotations[fields[0]+" "+fields[1]]
+		if !ok {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%w: %s: %s", ErrInvalidAnnotation, id, strings.TrimSpace(line))
+		}
+		d, err := time.ParseDuration(fields[2])
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("%w: %s: %s", ErrInvalidAnnotation, id, strings.TrimSpace(line))
+		}
+		settings = append(settings, fmt.Sprintf("SET LOCAL %s = '%dms';", name, d.Milliseconds()))
+	}
+	if len(settings) == 0 {
+		return m, nil
+	}
+
+	// SET LOCAL has no effect outside of a transaction.
+	if m.DisableTransactionUp || m.DisableTransactionDown {
+		return nil, fmt.Errorf("%w: %s: timeouts require a transaction", ErrInvalidAnnotation, id)
+	}
+	m.Up = append(append([]string{}, settings...), m.Up...)
+	if len(m.Down) > 0 {
+		m.Down = append(append([]string{}, settings...), m.Down...)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,59 @@
+package migrator
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// WritePlan writes the SQL of the planned migrations to w,
+// each migration prefixed with a comment containing its id and direction.
+func WritePlan(w io.Writer, dir migrate.MigrationDirection, plan []*migrate.PlannedMigration) error {
+	name := "up"
+	if dir == migrate.Down {
+		name = "down"
+	}
+
+	for _, p := range plan {
+		if _, err := fmt.Fprintf(w, "-- %s (%s)\n", p.Id, name); err != nil {
+			return err
+		}
+		for _, q := range p.Queries {
+			q = strings.TrimSpace(q)
+			if q == "" {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "%s\n", q); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteStatus writes the migration statuses to w as a table.
+func WriteStatus(w io.Writer, statuses []Status) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MIGRATION\tSTATUS\tAPPLIED AT") // nolint:errcheck
+
+	for _, s := range statuses {
+		status, appliedAt := "pending", "-"
+		if s.Applied {
+			status, appliedAt = "applied", s.AppliedAt.UTC().Format(time.RFC3339)
+		}
+		if s.Missing {
+			status = "missing"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.ID, status, appliedAt) // nolint:errcheck
+	}
+
+	return tw.Flush()
+}
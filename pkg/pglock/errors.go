@@ -0,0 +1,9 @@
+package pglock
+
+import "errors"
+
+// Predefined errors.
+var (
+	ErrNotAcquired   = errors.New("lock is held by another session")
+	ErrAlreadyUnlock = errors.New("lock is already released")
+)
@@ -0,0 +1,147 @@
+package pglock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+type (
+	// Locker is the interface that provides the methods to acquire
+	// session-level Postgres advisory locks.
+	Locker interface {
+		// TryLock tries to acquire the lock for the given key without waiting.
+		// It returns ErrNotAcquired if the lock is held by another session.
+		TryLock(ctx context.Context, key string) (Lock, error)
+
+		// Lock waits for the lock for the given key until it is acquired
+		// or the context is done.
+		Lock(ctx context.Context, key string) (Lock, error)
+	}
+
+	// Lock is an acquired advisory lock.
+	Lock interface {
+		// Unlock releases the lock and returns the underlying connection to the pool.
+		Unlock(ctx context.Context) error
+	}
+
+	// locker is the implementation of the Locker interface.
+	locker struct {
+		db            *sql.DB
+		retryInterval time.Duration
+	}
+
+	// lock is the implementation of the Lock interface.
+	// Advisory locks are bound to the session, so the lock keeps the connection
+	// it was acquired on until it is released.
+	lock struct {
+		conn *sql.Conn
+		id   int64
+		once sync.Once
+	}
+
+	// Option is a function that configures the Locker.
+	Option func(*locker)
+)
+
+// NewLocker returns a new Locker instance.
+func NewLocker(db *sql.DB, opts ...Option) Locker {
+	l := &locker{
+		db:            db,
+		retryInterval: time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// WithRetryInterval sets the interval between attempts to acquire the lock in Lock.
+// Default is 1 second.
+func WithRetryInterval(d time.Duration) Option {
+	return func(l *locker) {
+		if d > 0 {
+			l.retryInterval = d
+		}
+	}
+}
+
+// Key converts the given string key to the int64 identifier of the advisory lock.
+func Key(key string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(key)) // nolint:errcheck // hash.Hash never returns an error
+	return int64(h.Sum64())
+}
+
+// TryLock tries to acquire the lock for the given key without waiting.
+// It returns ErrNotAcquired if the lock is held by another session.
+func (l *locker) TryLock(ctx context.Context, key string) (Lock, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get db connection: %w", err)
+	}
+
+	id := Key(key)
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", id).Scan(&acquired); err != nil {
+		conn.Close() // nolint:errcheck
+		return nil, fmt.Errorf("failed to acquire advisory lock %q: %w", key, err)
+	}
+	if !acquired {
+		conn.Close() // nolint:errcheck
+		return nil, ErrNotAcquired
+	}
+
+	return &lock{conn: conn, id: id}, nil
+}
+
+// Lock waits for the lock for the given key until it is acquired
+// or the context is done.
+func (l *locker) Lock(ctx context.Context, key string) (Lock, error) {
+	ticker := time.NewTicker(l.retryInterval)
+	defer ticker.Stop()
+
+	for {
+		lk, err := l.TryLock(ctx, key)
+		if err == nil {
+			return lk, nil
+		}
+		if err != ErrNotAcquired {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to acquire advisory lock %q: %w", key, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Unlock releases the lock and returns the underlying connection to the pool.
+func (l *lock) Unlock(ctx context.Context) error {
+	err := ErrAlreadyUnlock
+	l.once.Do(func() {
+		defer l.conn.Close() // nolint:errcheck
+
+		var released bool
+		if err = l.conn.QueryRowContext(ctx, "SELECT pg_advisory_unlock($1)", l.id).Scan(&released); err != nil {
+			// The session may still hold the lock, so the connection must not
+			// be returned to the pool. Postgres releases the lock on disconnect.
+			l.conn.Raw(func(interface{}) error { return driver.ErrBadConn }) // nolint:errcheck
+			err = fmt.Errorf("failed to release advisory lock: %w", err)
+			return
+		}
+		if !released {
+			err = ErrAlreadyUnlock
+		}
+	})
+	return err
+}
@@ -0,0 +1,171 @@
+package pgschema
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// header is the first line of the schema snapshot.
+const header = "# Code generated by migrate schema dump. DO NOT EDIT."
+
+type (
+	// Schema is the canonical snapshot of the database schema:
+	// sorted lines, each describing a single object, e.g.
+	//
+	//	table users column email text NOT NULL
+	//	table users index users_email_key CREATE UNIQUE INDEX users_email_key ON users USING btree (email)
+	//	function update_updated_at_column() RETURNS trigger LANGUAGE plpgsql BODY 8d7f...
+	Schema []string
+
+	// Option is a function that configures the dump.
+	Option func(*options)
+
+	options struct {
+		excludedTables map[string]bool
+	}
+)
+
+// WithExcludedTables excludes the tables and their objects from the dump,
+// e.g. the tables of the migration tool which differ between environments.
+func WithExcludedTables(tables ...string) Option {
+	return func(o *options) {
+		for _, t := range tables {
+			o.excludedTables[t] = true
+		}
+	}
+}
+
+// Dump returns the snapshot of the current schema of the database connection,
+// i.e. the first schema of the search path. Names qualified with the current schema
+// are unqualified, so snapshots of the same objects in different schemas are equal.
+func Dump(ctx context.Context, db *sql.DB, opts ...Option) (Schema, error) {
+	o := &options{excludedTables: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var current string
+	if err := db.QueryRowContext(ctx, "SELECT quote_ident(current_schema())").Scan(&current); err != nil {
+		return nil, fmt.Errorf("failed to get current schema: %w", err)
+	}
+	qualified := regexp.MustCompile(`(^|[^\w$".])` + regexp.QuoteMeta(current+"."))
+
+	var schema Schema
+	for _, q := range queries {
+		rows, err := db.QueryContext(ctx, q)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dump schema: %w", err)
+		}
+
+		for rows.Next() {
+			var table, kind, name, def string
+			if err := rows.Scan(&table, &kind, &name, &def); err != nil {
+				rows.Close() // nolint:errcheck
+				return nil, fmt.Errorf("failed to scan schema object: %w", err)
+			}
+			if o.excludedTables[table] {
+				continue
+			}
+			// Definitions qualify the names, e.g. "ON public.sessions"
+			def = qualified.ReplaceAllString(def, "$1")
+			schema = append(schema, line(table, kind, name, def))
+		}
+		rows.Close() // nolint:errcheck
+
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to dump schema: %w", err)
+		}
+	}
+
+	sort.Strings(schema)
+	return schema, nil
+}
+
+// line returns the snapshot line describing the object.
+func line(table, kind, name, def string) string {
+	parts := make([]string, 0, 5)
+	if table != "" && kind != "table" {
+		parts = append(parts, "table", table)
+	}
+	parts = append(parts, kind, name)
+	if def != "" {
+		parts = append(parts, strings.Join(strings.Fields(def), " "))
+	}
+	return strings.Join(parts, " ")
+}
+
+// String returns the snapshot file content.
+func (s Schema) String() string {
+	var b strings.Builder
+	b.WriteString(header + "\n")
+	for _, l := range s {
+		b.WriteString(l + "\n")
+	}
+	return b.String()
+}
+
+// Parse reads the snapshot file, comments and empty lines are skipped.
+func Parse(r io.Reader) (Schema, error) {
+	var schema Schema
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		l := strings.TrimSpace(scanner.Text())
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		schema = append(schema, l)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read schema snapshot: %w", err)
+	}
+
+	sort.Strings(schema)
+	return schema, nil
+}
+
+// Diff returns the lines missing in the actual schema prefixed with "-"
+// and the unexpected lines of the actual schema prefixed with "+", ordered by line.
+// An empty result means the schemas are equal.
+func Diff(expected, actual Schema) []string {
+	count := make(map[string]int, len(expected))
+	for _, l := range expected {
+		count[l]++
+	}
+	for _, l := range actual {
+		count[l]--
+	}
+
+	var diff []string
+	for _, l := range uniq(expected, actual) {
+		switch n := count[l]; {
+		case n > 0:
+			diff = append(diff, "- "+l)
+		case n < 0:
+			diff = append(diff, "+ "+l)
+		}
+	}
+	return diff
+}
+
+// uniq returns the sorted distinct lines of both schemas.
+func uniq(a, b Schema) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var result []string
+	for _, s := range [][]string{a, b} {
+		for _, l := range s {
+			if !seen[l] {
+				seen[l] = true
+				result = append(result, l)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
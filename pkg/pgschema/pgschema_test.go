@@ -0,0 +1,80 @@
+package pgschema_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dmitrymomot/go-app/internal/repository/repotest"
+	"github.com/dmitrymomot/go-app/pkg/pgschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	schema := pgschema.Schema{
+		"table users",
+		"table users column email text NOT NULL",
+		"table users column id integer NOT NULL",
+	}
+
+	parsed, err := pgschema.Parse(strings.NewReader(schema.String()))
+	require.NoError(t, err)
+	require.Equal(t, schema, parsed)
+	require.True(t, strings.HasPrefix(schema.String(), "# Code generated"))
+
+	// Lines are sorted and comments are skipped
+	parsed, err = pgschema.Parse(strings.NewReader("# comment\n\ntable users column id integer NOT NULL\ntable users\n"))
+	require.NoError(t, err)
+	require.Equal(t, pgschema.Schema{"table users", "table users column id integer NOT NULL"}, parsed)
+}
+
+func TestDiff(t *testing.T) {
+	expected := pgschema.Schema{
+		"table users",
+		"table users column email text NOT NULL",
+		"table users column id integer NOT NULL",
+	}
+
+	// Test case 1: equal schemas
+	t.Run("equal schemas", func(t *testing.T) {
+		require.Empty(t, pgschema.Diff(expected, expected))
+	})
+
+	// Test case 2: changed, missing and unexpected objects
+	t.Run("changed objects", func(t *testing.T) {
+		actual := pgschema.Schema{
+			"table posts",
+			"table users",
+			"table users column email text",
+			"table users column id integer NOT NULL",
+		}
+		require.Equal(t, []string{
+			"+ table posts",
+			"+ table users column email text",
+			"- table users column email text NOT NULL",
+		}, pgschema.Diff(expected, actual))
+	})
+}
+
+func TestDump(t *testing.T) {
+	ctx := context.Background()
+	opts := []pgschema.Option{pgschema.WithExcludedTables("migrations", "migrations_checksums", "seeds")}
+
+	// Every call provisions a new schema with all migrations applied
+	first, err := pgschema.Dump(ctx, repotest.DB(t), opts...)
+	require.NoError(t, err)
+	second, err := pgschema.Dump(ctx, repotest.DB(t), opts...)
+	require.NoError(t, err)
+
+	// Test case 1: dumps of the same migrations in different schemas are equal
+	t.Run("schema independent", func(t *testing.T) {
+		require.NotEmpty(t, first)
+		require.Equal(t, first, second)
+		require.NotContains(t, first.String(), "test_")
+	})
+
+	// Test case 2: excluded tables are skipped
+	t.Run("excluded tables", func(t *testing.T) {
+		require.NotContains(t, first, "table migrations")
+	})
+}
@@ -0,0 +1,67 @@
+package pgschema
+
+// Queries return rows of (table, kind, name, definition) describing the objects
+// of the current schema. Definitions are built by Postgres, so they are the same
+// for the same schema regardless of how the objects were created.
+// Objects owned by extensions are skipped.
+var queries = []string{
+	// Extensions
+	`SELECT '', 'extension', e.extname, ''
+	FROM pg_extension e
+	WHERE e.extname <> 'plpgsql'`,
+
+	// Enum types
+	`SELECT '', 'type', t.typname, 'ENUM (' || string_agg(quote_literal(e.enumlabel), ', ' ORDER BY e.enumsortorder) || ')'
+	FROM pg_type t
+	JOIN pg_enum e ON e.enumtypid = t.oid
+	JOIN pg_namespace n ON n.oid = t.typnamespace
+	WHERE n.nspname = current_schema()
+	AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.objid = t.oid AND d.deptype = 'e')
+	GROUP BY t.typname`,
+
+	// Tables
+	`SELECT c.relname, 'table', c.relname, ''
+	FROM pg_class c
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = current_schema() AND c.relkind IN ('r', 'p')`,
+
+	// Columns
+	`SELECT c.relname, 'column', a.attname,
+		format_type(a.atttypid, a.atttypmod)
+		|| CASE WHEN a.attnotnull THEN ' NOT NULL' ELSE '' END
+		|| COALESCE(' DEFAULT ' || pg_get_expr(d.adbin, d.adrelid), '')
+	FROM pg_attribute a
+	JOIN pg_class c ON c.oid = a.attrelid
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+	WHERE n.nspname = current_schema() AND c.relkind IN ('r', 'p')
+	AND a.attnum > 0 AND NOT a.attisdropped`,
+
+	// Constraints
+	`SELECT c.relname, 'constraint', con.conname, pg_get_constraintdef(con.oid)
+	FROM pg_constraint con
+	JOIN pg_class c ON c.oid = con.conrelid
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = current_schema()`,
+
+	// Indexes
+	`SELECT tablename, 'index', indexname, indexdef
+	FROM pg_indexes
+	WHERE schemaname = current_schema()`,
+
+	// Triggers
+	`SELECT c.relname, 'trigger', t.tgname, pg_get_triggerdef(t.oid)
+	FROM pg_trigger t
+	JOIN pg_class c ON c.oid = t.tgrelid
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = current_schema() AND NOT t.tgisinternal`,
+
+	// Functions, the body is hashed to keep the snapshot line based
+	`SELECT '', 'function', p.proname || '(' || pg_get_function_identity_arguments(p.oid) || ')',
+		'RETURNS ' || pg_get_function_result(p.oid) || ' LANGUAGE ' || l.lanname || ' BODY ' || md5(p.prosrc)
+	FROM pg_proc p
+	JOIN pg_namespace n ON n.oid = p.pronamespace
+	JOIN pg_language l ON l.oid = p.prolang
+	WHERE n.nspname = current_schema()
+	AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.objid = p.oid AND d.deptype = 'e')`,
+}
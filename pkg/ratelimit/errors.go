@@ -0,0 +1,10 @@
+package ratelimit
+
+import "errors"
+
+// Predefined errors.
+var (
+	ErrLimitExceeded    = errors.New("too_many_requests")
+	ErrInvalidLimit     = errors.New("rate limit must have positive requests and period")
+	ErrUnknownAlgorithm = errors.New("unknown rate limit algorithm")
+)
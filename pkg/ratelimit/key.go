@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// unmatchedRoute is the route of the KeyByRoute key if the request is not routed yet.
+// Raw paths are not used, so clients can't create an unbounded number of keys.
+const unmatchedRoute = "unmatched"
+
+// KeyFunc returns the rate limit key of the request.
+// An empty key means the request is not limited.
+type KeyFunc func(r *http.Request) string
+
+// KeyByIP limits requests by the client IP address.
+// Use it after middleware.RealIP, so the IP of the client behind a proxy is used.
+func KeyByIP(r *http.Request) string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	return "ip:" + ip
+}
+
+// KeyBySubject limits requests by the authenticated subject returned by the given function,
+// e.g. the user ID from JWT claims. Anonymous requests are limited by IP.
+func KeyBySubject(subject func(r *http.Request) string) KeyFunc {
+	return func(r *http.Request) string {
+		if sub := subject(r); sub != "" {
+			return "sub:" + sub
+		}
+		return KeyByIP(r)
+	}
+}
+
+// KeyByHeader limits requests by the value of the given header, e.g. an API key.
+// The value is hashed, so secrets are not stored in plain text.
+// Requests without the header are limited by IP.
+//
+// Use it only for headers set by a trusted proxy or verified by an authentication
+// middleware installed before the limiter, otherwise clients bypass the limit
+// by sending a new value with every request.
+func KeyByHeader(name string) KeyFunc {
+	return func(r *http.Request) string {
+		value := r.Header.Get(name)
+		if value == "" {
+			return KeyByIP(r)
+		}
+		sum := sha256.Sum256([]byte(value))
+		return "hdr:" + strings.ToLower(name) + ":" + hex.EncodeToString(sum[:16])
+	}
+}
+
+// KeyByRoute limits requests by the route pattern in addition to the given key,
+// so every route has its own limit.
+// The pattern is known only after routing, so use it in route-level middleware,
+// e.g. r.With; requests which are not routed yet share the "unmatched" route.
+func KeyByRoute(key KeyFunc) KeyFunc {
+	return func(r *http.Request) string {
+		k := key(r)
+		if k == "" {
+			return ""
+		}
+		pattern := unmatchedRoute
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			pattern = rctx.RoutePattern()
+		}
+		return "route:" + r.Method + " " + pattern + ":" + k
+	}
+}
+
+// routePattern returns the matched chi route pattern or the URL path.
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return r.URL.Path
+}
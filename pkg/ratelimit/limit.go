@@ -0,0 +1,122 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Rate limiting algorithms.
+const (
+	// TokenBucket allows bursts up to Limit.Burst requests
+	// and refills the bucket at the rate of Limit.Requests per Limit.Period.
+	TokenBucket Algorithm = "token_bucket"
+	// SlidingWindow allows Limit.Requests per any Limit.Period interval,
+	// approximated with counters of the current and the previous windows.
+	SlidingWindow Algorithm = "sliding_window"
+)
+
+type (
+	// Algorithm is a rate limiting algorithm.
+	Algorithm string
+
+	// Limit describes how many requests are allowed per period.
+	Limit struct {
+		Algorithm Algorithm
+		Requests  int
+		Period    time.Duration
+		// Burst is the capacity of the token bucket, Requests is used if it's zero.
+		Burst int
+	}
+
+	// Result is the result of a rate limit check.
+	Result struct {
+		Allowed   bool
+		Limit     int
+		Remaining int
+		// ResetAfter is the time until the limit is fully restored.
+		ResetAfter time.Duration
+		// RetryAfter is the time until the next request is allowed, if it's denied.
+		RetryAfter time.Duration
+	}
+
+	// Store keeps the state of the rate limits.
+	Store interface {
+		// Allow checks and consumes one request for the given key.
+		Allow(ctx context.Context, key string, limit Limit) (Result, error)
+	}
+)
+
+// Unlimited is the limit which disables rate limiting, e.g. for a route override.
+var Unlimited = Limit{}
+
+// PerSecond returns the token bucket limit of n requests per second.
+func PerSecond(n int) Limit {
+	return Limit{Algorithm: TokenBucket, Requests: n, Period: time.Second}
+}
+
+// PerMinute returns the token bucket limit of n requests per minute.
+func PerMinute(n int) Limit {
+	return Limit{Algorithm: TokenBucket, Requests: n, Period: time.Minute}
+}
+
+// PerHour returns the token bucket limit of n requests per hour.
+func PerHour(n int) Limit {
+	return Limit{Algorithm: TokenBucket, Requests: n, Period: time.Hour}
+}
+
+// IsUnlimited returns true if the limit disables rate limiting.
+func (l Limit) IsUnlimited() bool {
+	return l.Requests <= 0 && l.Period <= 0
+}
+
+// Validate checks the limit.
+func (l Limit) Validate() error {
+	if l.IsUnlimited() {
+		return nil
+	}
+	if l.Requests <= 0 || l.Period <= 0 || l.Burst < 0 {
+		return ErrInvalidLimit
+	}
+	switch l.Algorithm {
+	case TokenBucket, SlidingWindow, "":
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownAlgorithm, l.Algorithm)
+}
+
+// capacity returns the capacity of the token bucket.
+func (l Limit) capacity() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// algorithm returns the algorithm of the limit, the token bucket is used by default.
+func (l Limit) algorithm() Algorithm {
+	if l.Algorithm == "" {
+		return TokenBucket
+	}
+	return l.Algorithm
+}
+
+// rate returns the refill rate of the token bucket in tokens per nanosecond.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / float64(l.Period)
+}
+
+// tokenBucketResult builds the result from the number of tokens left in the bucket.
+// It's shared by the memory and Redis stores.
+func tokenBucketResult(limit Limit, tokens float64, allowed bool) Result {
+	res := Result{
+		Allowed:    allowed,
+		Limit:      limit.capacity(),
+		Remaining:  int(tokens),
+		ResetAfter: time.Duration((float64(limit.capacity()) - tokens) / limit.rate()),
+	}
+	if !allowed {
+		res.RetryAfter = time.Duration((1 - tokens) / limit.rate())
+	}
+	return res
+}
@@ -0,0 +1,165 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type (
+	// memoryStore is an in-memory implementation of the Store interface.
+	// It's suitable for a single instance of the application and tests.
+	memoryStore struct {
+		mu        sync.Mutex
+		buckets   map[string]*bucket
+		windows   map[string]*window
+		lastSweep time.Time
+	}
+
+	// bucket is the state of the token bucket.
+	bucket struct {
+		tokens float64
+		last   time.Time
+		refill time.Duration // time to refill the empty bucket
+	}
+
+	// window is the state of the sliding window.
+	window struct {
+		start    time.Time
+		current  int
+		previous int
+		period   time.Duration
+	}
+)
+
+// sweepInterval is the interval of removing stale entries from the memory store.
+const sweepInterval = time.Minute
+
+// NewMemoryStore returns a new in-memory Store instance.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		buckets:   make(map[string]*bucket),
+		windows:   make(map[string]*window),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow checks and consumes one request for the given key.
+func (s *memoryStore) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	if err := limit.Validate(); err != nil {
+		return Result{}, err
+	}
+	if limit.IsUnlimited() {
+		return Result{Allowed: true}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	if limit.algorithm() == SlidingWindow {
+		return s.slidingWindow(key, limit, now), nil
+	}
+	return s.tokenBucket(key, limit, now), nil
+}
+
+// tokenBucket applies the token bucket algorithm.
+func (s *memoryStore) tokenBucket(key string, limit Limit, now time.Time) Result {
+	capacity := float64(limit.capacity())
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{
+			tokens: capacity,
+			last:   now,
+			refill: time.Duration(capacity / limit.rate()),
+		}
+		s.buckets[key] = b
+	}
+
+	b.tokens = math.Min(capacity, b.tokens+float64(now.Sub(b.last))*limit.rate())
+	b.last = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return tokenBucketResult(limit, b.tokens, allowed)
+}
+
+// slidingWindow applies the sliding window counter algorithm.
+func (s *memoryStore) slidingWindow(key string, limit Limit, now time.Time) Result {
+	start := now.Truncate(limit.Period)
+
+	w, ok := s.windows[key]
+	if !ok {
+		w = &window{start: start, period: limit.Period}
+		s.windows[key] = w
+	}
+
+	switch elapsed := start.Sub(w.start); {
+	case elapsed == limit.Period:
+		w.previous, w.current = w.current, 0
+	case elapsed > limit.Period:
+		w.previous, w.current = 0, 0
+	}
+	w.start = start
+
+	res := slidingWindowResult(limit, w.previous, w.current, now.Sub(start))
+	if res.Allowed {
+		w.current++
+	}
+	return res
+}
+
+// slidingWindowResult estimates the number of requests in the sliding window.
+// It's shared by the memory and Redis stores.
+func slidingWindowResult(limit Limit, previous, current int, elapsed time.Duration) Result {
+	weight := 1 - float64(elapsed)/float64(limit.Period)
+	estimated := float64(previous)*weight + float64(current)
+
+	res := Result{
+		Limit:      limit.Requests,
+		ResetAfter: limit.Period - elapsed,
+	}
+
+	if estimated+1 <= float64(limit.Requests) {
+		res.Allowed = true
+		res.Remaining = int(float64(limit.Requests) - estimated - 1)
+		return res
+	}
+
+	// Time until the weight of the previous window decreases enough to allow a request,
+	// or until the next window if the current one is already full.
+	if current < limit.Requests && previous > 0 {
+		needed := 1 - (float64(limit.Requests)-float64(current)-1)/float64(previous)
+		res.RetryAfter = time.Duration(needed*float64(limit.Period)) - elapsed
+	}
+	if res.RetryAfter <= 0 {
+		res.RetryAfter = limit.Period - elapsed
+	}
+	return res
+}
+
+// sweep removes stale entries to bound the memory usage.
+// The caller must hold the lock.
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if now.Sub(b.last) > b.refill {
+			delete(s.buckets, key)
+		}
+	}
+	for key, w := range s.windows {
+		if now.Sub(w.start) > 2*w.period {
+			delete(s.windows, key)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmitrymomot/go-pkg/response"
+	"github.com/go-chi/chi/v5"
+)
+
+type (
+	// Option is a function that configures the middleware.
+	Option func(*options)
+
+	options struct {
+		keyFunc      KeyFunc
+		routes       chi.Routes
+		routeLimits  map[string]Limit
+		errorHandler func(r *http.Request, err error)
+		limitHandler http.HandlerFunc
+	}
+)
+
+// Middleware limits the rate of requests with the given store and default limit.
+// Standard RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers are set,
+// rejected requests get 429 Too Many Requests with the Retry-After header.
+// If the store fails, the request is allowed.
+func Middleware(store Store, limit Limit, opts ...Option) func(http.Handler) http.Handler {
+	o := &options{
+		keyFunc:      KeyByIP,
+		routeLimits:  make(map[string]Limit),
+		errorHandler: func(*http.Request, error) {},
+		limitHandler: defaultLimitHandler,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := o.keyFunc(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			l := limit
+			if pattern, override, ok := o.routeLimit(r); ok {
+				// Overridden routes have their own counters.
+				l, key = override, pattern+":"+key
+			}
+			if l.IsUnlimited() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			res, err := store.Allow(r.Context(), key, l)
+			if err != nil {
+				o.errorHandler(r, err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", ceilSeconds(res.ResetAfter))
+
+			if !res.Allowed {
+				h.Set("Retry-After", ceilSeconds(res.RetryAfter))
+				o.limitHandler(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WithKeyFunc sets the function which returns the rate limit key of the request.
+// Default is KeyByIP.
+func WithKeyFunc(fn KeyFunc) Option {
+	return func(o *options) {
+		if fn != nil {
+			o.keyFunc = fn
+		}
+	}
+}
+
+// WithRouteLimits overrides the default limit for the given routes.
+// The keys are chi route patterns, optionally prefixed with the method,
+// e.g. "/health" or "POST /auth/login". Use Unlimited to exempt a route.
+// The routes are matched with the given router, so the middleware
+// can be installed globally with r.Use.
+func WithRouteLimits(routes chi.Routes, limits map[string]Limit) Option {
+	return func(o *options) {
+		o.routes = routes
+		for pattern, limit := range limits {
+			o.routeLimits[pattern] = limit
+		}
+	}
+}
+
+// WithErrorHandler sets the function called on store errors.
+func WithErrorHandler(fn func(r *http.Request, err error)) Option {
+	return func(o *options) {
+		if fn != nil {
+			o.errorHandler = fn
+		}
+	}
+}
+
+// WithLimitHandler sets the handler of rejected requests.
+// Default handler responds with 429 Too Many Requests.
+func WithLimitHandler(fn http.HandlerFunc) Option {
+	return func(o *options) {
+		if fn != nil {
+			o.limitHandler = fn
+		}
+	}
+}
+
+// routeLimit returns the overridden limit of the route matching the request.
+func (o *options) routeLimit(r *http.Request) (string, Limit, bool) {
+	if len(o.routeLimits) == 0 {
+		return "", Limit{}, false
+	}
+
+	pattern := routePattern(r)
+	if o.routes != nil {
+		rctx := chi.NewRouteContext()
+		if o.routes.Match(rctx, r.Method, r.URL.Path) {
+			pattern = rctx.RoutePattern()
+		}
+	}
+
+	// Mounted sub-routers add the "/*" suffix to the pattern.
+	pattern = strings.TrimSuffix(pattern, "/*")
+	for _, key := range []string{r.Method + " " + pattern, pattern} {
+		if l, ok := o.routeLimits[key]; ok {
+			return key, l, true
+		}
+	}
+	return "", Limit{}, false
+}
+
+// ceilSeconds formats the duration as a number of seconds rounded up.
+func ceilSeconds(d time.Duration) string {
+	if d <= 0 {
+		return "0"
+	}
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
+
+// defaultLimitHandler responds with 429 Too Many Requests.
+func defaultLimitHandler(w http.ResponseWriter, r *http.Request) {
+	resp := response.NewError(
+		http.StatusTooManyRequests,
+		ErrLimitExceeded,
+		fmt.Sprintf("Rate limit exceeded, retry in %s seconds.", w.Header().Get("Retry-After")),
+		nil,
+	)
+	response.JSON(w, resp) // nolint:errcheck
+}
@@ -0,0 +1,181 @@
+package ratelimit_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dmitrymomot/go-app/pkg/ratelimit"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+
+	// Test case 1: token bucket allows burst and refills
+	t.Run("token bucket allows burst and refills", func(t *testing.T) {
+		s := ratelimit.NewMemoryStore()
+		limit := ratelimit.Limit{Algorithm: ratelimit.TokenBucket, Requests: 10, Period: 100 * time.Millisecond, Burst: 3}
+
+		for i := 2; i >= 0; i-- {
+			res, err := s.Allow(ctx, "key", limit)
+			require.NoError(t, err)
+			require.True(t, res.Allowed)
+			require.Equal(t, 3, res.Limit)
+			require.Equal(t, i, res.Remaining)
+		}
+
+		res, err := s.Allow(ctx, "key", limit)
+		require.NoError(t, err)
+		require.False(t, res.Allowed)
+		require.Greater(t, res.RetryAfter, time.Duration(0))
+		require.LessOrEqual(t, res.RetryAfter, 10*time.Millisecond)
+
+		// Other keys have their own buckets.
+		res, err = s.Allow(ctx, "other", limit)
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+
+		time.Sleep(15 * time.Millisecond)
+		res, err = s.Allow(ctx, "key", limit)
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+	})
+
+	// Test case 2: sliding window limits requests per period
+	t.Run("sliding window limits requests per period", func(t *testing.T) {
+		s := ratelimit.NewMemoryStore()
+		limit := ratelimit.Limit{Algorithm: ratelimit.SlidingWindow, Requests: 3, Period: time.Hour}
+
+		for i := 2; i >= 0; i-- {
+			res, err := s.Allow(ctx, "key", limit)
+			require.NoError(t, err)
+			require.True(t, res.Allowed)
+			require.Equal(t, i, res.Remaining)
+		}
+
+		res, err := s.Allow(ctx, "key", limit)
+		require.NoError(t, err)
+		require.False(t, res.Allowed)
+		require.Greater(t, res.RetryAfter, time.Duration(0))
+		require.LessOrEqual(t, res.RetryAfter, time.Hour)
+	})
+
+	// Test case 3: invalid and unlimited limits
+	t.Run("invalid and unlimited limits", func(t *testing.T) {
+		s := ratelimit.NewMemoryStore()
+
+		_, err := s.Allow(ctx, "key", ratelimit.Limit{Requests: 1})
+		require.ErrorIs(t, err, ratelimit.ErrInvalidLimit)
+
+		_, err = s.Allow(ctx, "key", ratelimit.Limit{Algorithm: "unknown", Requests: 1, Period: time.Second})
+		require.ErrorIs(t, err, ratelimit.ErrUnknownAlgorithm)
+
+		res, err := s.Allow(ctx, "key", ratelimit.Unlimited)
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	newRouter := func() *chi.Mux {
+		r := chi.NewRouter()
+		r.Use(ratelimit.Middleware(ratelimit.NewMemoryStore(), ratelimit.PerHour(2),
+			ratelimit.WithRouteLimits(r, map[string]ratelimit.Limit{
+				"/health":           ratelimit.Unlimited,
+				"POST /auth/login":  ratelimit.PerHour(1),
+				"/users/{id}/posts": ratelimit.PerHour(3),
+			}),
+		))
+		ok := func(w http.ResponseWriter, r *http.Request) {}
+		r.Get("/", ok)
+		r.Get("/health", ok)
+		r.Post("/auth/login", ok)
+		r.Get("/users/{id}/posts", ok)
+		return r
+	}
+
+	do := func(r http.Handler, method, path, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = ip + ":1234"
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Test case 1: default limit by IP with headers
+	t.Run("default limit by IP with headers", func(t *testing.T) {
+		r := newRouter()
+
+		rec := do(r, http.MethodGet, "/", "10.0.0.1")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+		require.Equal(t, "1", rec.Header().Get("RateLimit-Remaining"))
+		require.NotEmpty(t, rec.Header().Get("RateLimit-Reset"))
+
+		require.Equal(t, http.StatusOK, do(r, http.MethodGet, "/", "10.0.0.1").Code)
+
+		rec = do(r, http.MethodGet, "/", "10.0.0.1")
+		require.Equal(t, http.StatusTooManyRequests, rec.Code)
+		require.NotEmpty(t, rec.Header().Get("Retry-After"))
+		require.Contains(t, rec.Body.String(), ratelimit.ErrLimitExceeded.Error())
+
+		// Another client is not limited.
+		require.Equal(t, http.StatusOK, do(r, http.MethodGet, "/", "10.0.0.2").Code)
+	})
+
+	// Test case 2: route overrides
+	t.Run("route overrides", func(t *testing.T) {
+		r := newRouter()
+
+		for i := 0; i < 5; i++ {
+			rec := do(r, http.MethodGet, "/health", "10.0.0.1")
+			require.Equal(t, http.StatusOK, rec.Code)
+			require.Empty(t, rec.Header().Get("RateLimit-Limit"))
+		}
+
+		require.Equal(t, http.StatusOK, do(r, http.MethodPost, "/auth/login", "10.0.0.1").Code)
+		require.Equal(t, http.StatusTooManyRequests, do(r, http.MethodPost, "/auth/login", "10.0.0.1").Code)
+
+		for i := 0; i < 3; i++ {
+			require.Equal(t, http.StatusOK, do(r, http.MethodGet, "/users/1/posts", "10.0.0.1").Code)
+		}
+		require.Equal(t, http.StatusTooManyRequests, do(r, http.MethodGet, "/users/2/posts", "10.0.0.1").Code)
+
+		// The default limit is not consumed by the overridden routes.
+		require.Equal(t, http.StatusOK, do(r, http.MethodGet, "/", "10.0.0.1").Code)
+	})
+}
+
+func TestKeyFuncs(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	require.Equal(t, "ip:10.0.0.1", ratelimit.KeyByIP(req))
+
+	require.Equal(t, "sub:user-1", ratelimit.KeyBySubject(func(*http.Request) string { return "user-1" })(req))
+	require.Equal(t, "ip:10.0.0.1", ratelimit.KeyBySubject(func(*http.Request) string { return "" })(req))
+
+	require.Equal(t, "ip:10.0.0.1", ratelimit.KeyByHeader("X-API-Key")(req))
+	req.Header.Set("X-API-Key", "secret")
+	key := ratelimit.KeyByHeader("X-API-Key")(req)
+	require.Contains(t, key, "hdr:x-api-key:")
+	require.NotContains(t, key, "secret")
+
+	// Raw paths of requests which are not routed yet are not used
+	req.URL.Path = "/random/1"
+	byRoute := ratelimit.KeyByRoute(ratelimit.KeyByIP)
+	require.Equal(t, "route:GET unmatched:ip:10.0.0.1", byRoute(req))
+
+	r := chi.NewRouter()
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(byRoute(r))) // nolint:errcheck
+	})
+	rec := httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	r.ServeHTTP(rec, req)
+	require.Equal(t, "route:GET /users/{id}:ip:10.0.0.1", rec.Body.String())
+}
@@ -0,0 +1,147 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisStore is a Redis implementation of the Store interface.
+// The limits are shared between all instances of the application.
+type redisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// tokenBucketScript applies the token bucket algorithm atomically.
+// The Redis server time is used, so the clocks of the instances don't matter.
+//
+// KEYS[1] - bucket key
+// ARGV[1] - capacity, ARGV[2] - refill rate in tokens per microsecond, ARGV[3] - key TTL in milliseconds
+var tokenBucketScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+tokens = math.min(capacity, tokens + (now - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], ARGV[3])
+return {allowed, tostring(tokens)}
+`)
+
+// slidingWindowScript applies the sliding window counter algorithm atomically.
+//
+// KEYS[1] - window key
+// ARGV[1] - period in milliseconds, ARGV[2] - requests limit
+var slidingWindowScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local period = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local start = now - (now % period)
+local state = redis.call("HMGET", KEYS[1], "start", "current", "previous")
+local current = tonumber(state[2]) or 0
+local previous = tonumber(state[3]) or 0
+local last = tonumber(state[1]) or start
+if start - last == period then
+	previous = current
+	current = 0
+elseif start - last > period then
+	previous = 0
+	current = 0
+end
+local elapsed = now - start
+local allowed = 0
+if previous * (1 - elapsed / period) + current + 1 <= limit then
+	allowed = 1
+end
+redis.call("HSET", KEYS[1], "start", start, "current", current + allowed, "previous", previous)
+redis.call("PEXPIRE", KEYS[1], period * 2)
+return {allowed, previous, current, elapsed}
+`)
+
+// NewRedisStore returns a new Store instance backed by Redis.
+// If the key prefix is empty, "ratelimit:" is used.
+func NewRedisStore(client redis.UniversalClient, prefix string) Store {
+	if prefix == "" {
+		prefix = "ratelimit:"
+	}
+	return &redisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Allow checks and consumes one request for the given key.
+func (s *redisStore) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	if err := limit.Validate(); err != nil {
+		return Result{}, err
+	}
+	if limit.IsUnlimited() {
+		return Result{Allowed: true}, nil
+	}
+
+	if limit.algorithm() == SlidingWindow {
+		return s.slidingWindow(ctx, key, limit)
+	}
+	return s.tokenBucket(ctx, key, limit)
+}
+
+// tokenBucket applies the token bucket algorithm.
+func (s *redisStore) tokenBucket(ctx context.Context, key string, limit Limit) (Result, error) {
+	ratePerMicrosecond := limit.rate() * float64(time.Microsecond)
+	ttl := time.Duration(float64(limit.capacity())/limit.rate()) + time.Second
+
+	res, err := tokenBucketScript.Run(ctx, s.client,
+		[]string{s.prefix + "tb:" + key},
+		limit.capacity(), strconv.FormatFloat(ratePerMicrosecond, 'f', -1, 64), ttl.Milliseconds(),
+	).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to apply rate limit: %w", err)
+	}
+	if len(res) != 2 {
+		return Result{}, fmt.Errorf("failed to apply rate limit: unexpected script result %v", res)
+	}
+
+	allowed, _ := res[0].(int64)
+	tokensStr, _ := res[1].(string)
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to apply rate limit: %w", err)
+	}
+
+	return tokenBucketResult(limit, tokens, allowed == 1), nil
+}
+
+// slidingWindow applies the sliding window counter algorithm.
+func (s *redisStore) slidingWindow(ctx context.Context, key string, limit Limit) (Result, error) {
+	res, err := slidingWindowScript.Run(ctx, s.client,
+		[]string{s.prefix + "sw:" + key},
+		limit.Period.Milliseconds(), limit.Requests,
+	).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to apply rate limit: %w", err)
+	}
+	if len(res) != 4 {
+		return Result{}, fmt.Errorf("failed to apply rate limit: unexpected script result %v", res)
+	}
+
+	result := slidingWindowResult(limit, int(res[1]), int(res[2]), time.Duration(res[3])*time.Millisecond)
+	// The decision is made by the script, the result is only used for the headers.
+	result.Allowed = res[0] == 1
+	if result.Allowed {
+		result.RetryAfter = 0
+	}
+	return result, nil
+}
@@ -0,0 +1,12 @@
+package scheduler
+
+import "errors"
+
+// Predefined errors.
+var (
+	ErrInvalidJobName    = errors.New("job name must not be empty")
+	ErrInvalidJobFunc    = errors.New("job function must not be nil")
+	ErrJobAlreadyExists  = errors.New("job with the same name is already registered")
+	ErrInvalidCronSpec   = errors.New("invalid cron expression")
+	ErrSchedulerIsActive = errors.New("scheduler is already running")
+)
@@ -0,0 +1,61 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WithLocker sets the locker used for leader election.
+// Without a locker every instance runs every tick.
+func WithLocker(l Locker) Option {
+	return func(s *Scheduler) {
+		s.locker = l
+	}
+}
+
+// WithStore sets the store of the job states.
+// Default is the in-memory store.
+func WithStore(store Store) Option {
+	return func(s *Scheduler) {
+		if store != nil {
+			s.store = store
+		}
+	}
+}
+
+// WithLogger sets the logger for the scheduler.
+// Default is the logrus standard logger.
+func WithLogger(l logrus.FieldLogger) Option {
+	return func(s *Scheduler) {
+		if l != nil {
+			s.logger = l
+		}
+	}
+}
+
+// WithLocation sets the time zone used to evaluate cron expressions.
+// Default is UTC.
+func WithLocation(loc *time.Location) Option {
+	return func(s *Scheduler) {
+		if loc != nil {
+			s.location = loc
+		}
+	}
+}
+
+// WithJobTimeout sets the maximum duration of a single job run.
+// Zero disables the timeout. Default is 5 minutes.
+func WithJobTimeout(d time.Duration) Option {
+	return func(s *Scheduler) {
+		s.jobTimeout = d
+	}
+}
+
+// WithLockPrefix sets the prefix of the job lock keys.
+// Default is "scheduler:".
+func WithLockPrefix(prefix string) Option {
+	return func(s *Scheduler) {
+		s.lockPrefix = prefix
+	}
+}
@@ -0,0 +1,282 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dmitrymomot/go-app/pkg/pglock"
+	"github.com/dmitrymomot/go-app/pkg/tracing"
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer is used to trace job runs.
+var tracer = otel.Tracer("github.com/dmitrymomot/go-app/pkg/scheduler")
+
+type (
+	// JobFunc is a function which is executed on every tick of the job schedule.
+	JobFunc func(ctx context.Context) error
+
+	// Locker is used to elect a single instance which runs the job tick.
+	// pglock.Locker implements this interface.
+	Locker interface {
+		TryLock(ctx context.Context, key string) (pglock.Lock, error)
+	}
+
+	// Scheduler runs registered jobs according to their cron schedules.
+	// When a Locker is configured, every tick is executed by one instance only.
+	Scheduler struct {
+		mu      sync.Mutex
+		jobs    []*job
+		names   map[string]struct{}
+		running bool
+
+		parser     cron.Parser
+		locker     Locker
+		store      Store
+		logger     logrus.FieldLogger
+		location   *time.Location
+		jobTimeout time.Duration
+		lockPrefix string
+	}
+
+	// job is a registered periodic job.
+	job struct {
+		name     string
+		spec     string
+		schedule cron.Schedule
+		fn       JobFunc
+	}
+
+	// Option is a function that configures the Scheduler.
+	Option func(*Scheduler)
+)
+
+// New creates a new Scheduler instance.
+// By default, the scheduler keeps job states in memory and does not use
+// leader election, which is suitable for a single instance only.
+func New(opts ...Option) *Scheduler {
+	s := &Scheduler{
+		names: make(map[string]struct{}),
+		parser: cron.NewParser(
+			cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+		),
+		store:      NewMemoryStore(),
+		logger:     logrus.StandardLogger(),
+		location:   time.UTC,
+		jobTimeout: 5 * time.Minute,
+		lockPrefix: "scheduler:",
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Add registers a new job with the given cron expression.
+// Both the standard 5-field format and the format with seconds are supported,
+// as well as descriptors like "@hourly" or "@every 10m".
+func (s *Scheduler) Add(name, spec string, fn JobFunc) error {
+	if name == "" {
+		return ErrInvalidJobName
+	}
+	if fn == nil {
+		return ErrInvalidJobFunc
+	}
+
+	schedule, err := s.parser.Parse(spec)
+	if err != nil {
+		return fmt.Errorf("%w %q: %v", ErrInvalidCronSpec, spec, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return ErrSchedulerIsActive
+	}
+	if _, ok := s.names[name]; ok {
+		return fmt.Errorf("%w: %s", ErrJobAlreadyExists, name)
+	}
+
+	s.names[name] = struct{}{}
+	s.jobs = append(s.jobs, &job{
+		name:     name,
+		spec:     spec,
+		schedule: schedule,
+		fn:       fn,
+	})
+
+	return nil
+}
+
+// Run starts the scheduler and blocks until the context is canceled.
+// It waits for the running jobs to finish before returning,
+// so it can be used in an error group:
+//
+//	eg.Go(func() error { return sched.Run(ctx) })
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return ErrSchedulerIsActive
+	}
+	s.running = true
+	jobs := s.jobs
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		wg.Add(1)
+		go func(j *job) {
+			defer wg.Done()
+			s.loop(ctx, j)
+		}(j)
+	}
+
+	s.logger.Infof("Scheduler started with %d jobs", len(jobs))
+	wg.Wait()
+	s.logger.Info("Scheduler stopped")
+
+	return nil
+}
+
+// loop waits for the next tick of the job schedule and runs the job
+// until the context is canceled.
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	for {
+		now := time.Now().In(s.location)
+		tick := j.next(now)
+		if tick.IsZero() {
+			s.logger.WithField("job", j.name).Warn("Job schedule has no next tick, stop scheduling")
+			return
+		}
+
+		timer := time.NewTimer(tick.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.runTick(ctx, j, tick)
+		}
+	}
+}
+
+// runTick runs the job for the given tick, if no other instance did it.
+func (s *Scheduler) runTick(ctx context.Context, j *job, tick time.Time) {
+	log := s.logger.WithFields(logrus.Fields{
+		"job":  j.name,
+		"tick": tick.Format(time.RFC3339),
+	})
+
+	if s.locker != nil {
+		lock, err := s.locker.TryLock(ctx, s.lockPrefix+j.name)
+		if err != nil {
+			if errors.Is(err, pglock.ErrNotAcquired) {
+				log.Debug("Job is being run by another instance, skip tick")
+				return
+			}
+			log.WithError(err).Error("Failed to acquire job lock")
+			return
+		}
+		defer func() {
+			// Use a fresh context, so the lock is released even on shutdown.
+			unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := lock.Unlock(unlockCtx); err != nil {
+				log.WithError(err).Warn("Failed to release job lock")
+			}
+		}()
+	}
+
+	// The lock only guarantees that the tick is not run concurrently,
+	// so the state is checked to skip ticks already handled by another instance.
+	state, err := s.store.Get(ctx, j.name)
+	if err != nil {
+		log.WithError(err).Error("Failed to get job state")
+		return
+	}
+	if state != nil && !state.LastTickAt.Before(tick) {
+		log.Debug("Job tick has already been run, skip tick")
+		return
+	}
+
+	if err := s.store.Start(ctx, j.name, tick); err != nil {
+		log.WithError(err).Error("Failed to record job start")
+		return
+	}
+
+	// Every run is a root span, so the job queries are traced as its children.
+	spanCtx, span := tracer.Start(ctx, "job "+j.name, trace.WithAttributes(
+		attribute.String("job.name", j.name),
+		attribute.String("job.tick", tick.Format(time.RFC3339)),
+	))
+	log = log.WithFields(tracing.LogFields(spanCtx))
+
+	started := time.Now()
+	jobErr := s.execute(spanCtx, j)
+	if jobErr != nil {
+		span.RecordError(jobErr)
+		span.SetStatus(codes.Error, jobErr.Error())
+	}
+	span.End()
+	log = log.WithField("duration", time.Since(started).String())
+
+	status := StatusSucceeded
+	if jobErr != nil {
+		status = StatusFailed
+		log.WithError(jobErr).Error("Job failed")
+	} else {
+		log.Info("Job succeeded")
+	}
+
+	// The job result must be recorded even if the scheduler is stopping.
+	finishCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.store.Finish(finishCtx, j.name, status, jobErr); err != nil {
+		log.WithError(err).Error("Failed to record job result")
+	}
+}
+
+// execute runs the job function with the configured timeout and recovers from panics.
+func (s *Scheduler) execute(ctx context.Context, j *job) (err error) {
+	if s.jobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.jobTimeout)
+		defer cancel()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return j.fn(ctx)
+}
+
+// next returns the next tick of the job schedule after the given time.
+// Constant delay schedules ("@every 10m") are aligned to the Unix epoch,
+// so all instances compute the same ticks regardless of their start time.
+func (j *job) next(now time.Time) time.Time {
+	if sch, ok := j.schedule.(cron.ConstantDelaySchedule); ok {
+		return now.Truncate(sch.Delay).Add(sch.Delay)
+	}
+	return j.schedule.Next(now)
+}
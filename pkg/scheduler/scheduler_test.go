@@ -0,0 +1,119 @@
+package scheduler_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dmitrymomot/go-app/pkg/pglock"
+	"github.com/dmitrymomot/go-app/pkg/scheduler"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryLocker is an in-memory implementation of the scheduler.Locker interface.
+type memoryLocker struct {
+	mu    sync.Mutex
+	locks map[string]bool
+}
+
+type memoryLock struct {
+	l   *memoryLocker
+	key string
+}
+
+func (l *memoryLocker) TryLock(_ context.Context, key string) (pglock.Lock, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.locks[key] {
+		return nil, pglock.ErrNotAcquired
+	}
+	l.locks[key] = true
+	return &memoryLock{l: l, key: key}, nil
+}
+
+func (lk *memoryLock) Unlock(context.Context) error {
+	lk.l.mu.Lock()
+	defer lk.l.mu.Unlock()
+	delete(lk.l.locks, lk.key)
+	return nil
+}
+
+func TestScheduler_Add(t *testing.T) {
+	noop := func(context.Context) error { return nil }
+
+	// Test case 1: valid cron expressions
+	t.Run("valid cron expressions", func(t *testing.T) {
+		s := scheduler.New()
+		require.NoError(t, s.Add("standard", "*/5 * * * *", noop))
+		require.NoError(t, s.Add("with-seconds", "0 */10 * * * *", noop))
+		require.NoError(t, s.Add("descriptor", "@hourly", noop))
+		require.NoError(t, s.Add("every", "@every 10m", noop))
+	})
+
+	// Test case 2: invalid job definitions
+	t.Run("invalid job definitions", func(t *testing.T) {
+		s := scheduler.New()
+		require.ErrorIs(t, s.Add("", "@hourly", noop), scheduler.ErrInvalidJobName)
+		require.ErrorIs(t, s.Add("job", "@hourly", nil), scheduler.ErrInvalidJobFunc)
+		require.ErrorIs(t, s.Add("job", "not a cron", noop), scheduler.ErrInvalidCronSpec)
+	})
+
+	// Test case 3: duplicated job name
+	t.Run("duplicated job name", func(t *testing.T) {
+		s := scheduler.New()
+		require.NoError(t, s.Add("job", "@hourly", noop))
+		require.ErrorIs(t, s.Add("job", "@daily", noop), scheduler.ErrJobAlreadyExists)
+	})
+}
+
+func TestScheduler_Run(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping scheduler run test in short mode")
+	}
+
+	// Test case 1: every tick is run once across several instances
+	t.Run("every tick is run once across several instances", func(t *testing.T) {
+		var (
+			mu    sync.Mutex
+			ticks = make(map[int64]int)
+		)
+		job := func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			ticks[time.Now().Truncate(time.Second).Unix()]++
+			return nil
+		}
+
+		store := scheduler.NewMemoryStore()
+		locker := &memoryLocker{locks: make(map[string]bool)}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			s := scheduler.New(scheduler.WithStore(store), scheduler.WithLocker(locker))
+			require.NoError(t, s.Add("job", "@every 1s", job))
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				require.NoError(t, s.Run(ctx))
+			}()
+		}
+		wg.Wait()
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.NotEmpty(t, ticks)
+		for tick, n := range ticks {
+			require.Equal(t, 1, n, "tick %d has been run %d times", tick, n)
+		}
+
+		state, err := store.Get(context.Background(), "job")
+		require.NoError(t, err)
+		require.NotNil(t, state)
+		require.Equal(t, scheduler.StatusSucceeded, state.Status)
+	})
+}
@@ -0,0 +1,99 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Job run statuses.
+const (
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+type (
+	// Status is a status of the last job run.
+	Status string
+
+	// JobState is a state of the last run of the job.
+	JobState struct {
+		Name       string
+		LastTickAt time.Time // scheduled time of the last run
+		StartedAt  time.Time
+		FinishedAt time.Time
+		Status     Status
+		Error      string
+	}
+
+	// Store is the interface that persists the state of the job runs.
+	// It is shared between all instances of the application, so it is used
+	// to detect whether a tick has already been handled by another instance.
+	Store interface {
+		// Get returns the state of the last run of the job.
+		// It returns nil if the job has never been run.
+		Get(ctx context.Context, name string) (*JobState, error)
+
+		// Start records that the job has been started for the given tick.
+		Start(ctx context.Context, name string, tick time.Time) error
+
+		// Finish records the result of the job run.
+		Finish(ctx context.Context, name string, status Status, jobErr error) error
+	}
+
+	// memoryStore is an in-memory implementation of the Store interface.
+	// It is suitable for a single instance of the application and tests.
+	memoryStore struct {
+		mu     sync.RWMutex
+		states map[string]JobState
+	}
+)
+
+// NewMemoryStore returns a new in-memory Store instance.
+func NewMemoryStore() Store {
+	return &memoryStore{states: make(map[string]JobState)}
+}
+
+// Get returns the state of the last run of the job.
+func (s *memoryStore) Get(_ context.Context, name string) (*JobState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, ok := s.states[name]
+	if !ok {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+// Start records that the job has been started for the given tick.
+func (s *memoryStore) Start(_ context.Context, name string, tick time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.states[name] = JobState{
+		Name:       name,
+		LastTickAt: tick,
+		StartedAt:  time.Now(),
+		Status:     StatusRunning,
+	}
+	return nil
+}
+
+// Finish records the result of the job run.
+func (s *memoryStore) Finish(_ context.Context, name string, status Status, jobErr error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.states[name]
+	state.Name = name
+	state.FinishedAt = time.Now()
+	state.Status = status
+	state.Error = ""
+	if jobErr != nil {
+		state.Error = jobErr.Error()
+	}
+	s.states[name] = state
+	return nil
+}
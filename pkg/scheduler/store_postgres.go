@@ -0,0 +1,91 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type (
+	// postgresStore is a Postgres implementation of the Store interface.
+	// The table is created by the application migrations,
+	// see internal/repository/sql/migrations.
+	postgresStore struct {
+		db    *sql.DB
+		table string
+	}
+)
+
+// NewPostgresStore returns a new Store instance backed by the given table.
+// If the table name is empty, "scheduler_jobs" is used.
+func NewPostgresStore(db *sql.DB, table string) Store {
+	if table == "" {
+		table = "scheduler_jobs"
+	}
+	return &postgresStore{
+		db:    db,
+		table: pq.QuoteIdentifier(table),
+	}
+}
+
+// Get returns the state of the last run of the job.
+func (s *postgresStore) Get(ctx context.Context, name string) (*JobState, error) {
+	var (
+		state      = &JobState{Name: name}
+		finishedAt sql.NullTime
+		lastError  sql.NullString
+	)
+
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf(
+		`SELECT last_tick_at, last_started_at, last_finished_at, last_status, last_error FROM %s WHERE name = $1`,
+		s.table,
+	), name).Scan(&state.LastTickAt, &state.StartedAt, &finishedAt, &state.Status, &lastError)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get job state: %w", err)
+	}
+
+	state.FinishedAt = finishedAt.Time
+	state.Error = lastError.String
+
+	return state, nil
+}
+
+// Start records that the job has been started for the given tick.
+func (s *postgresStore) Start(ctx context.Context, name string, tick time.Time) error {
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(
+		`INSERT INTO %s (name, last_tick_at, last_started_at, last_finished_at, last_status, last_error)
+		VALUES ($1, $2, NOW(), NULL, $3, NULL)
+		ON CONFLICT (name) DO UPDATE SET
+			last_tick_at = EXCLUDED.last_tick_at,
+			last_started_at = EXCLUDED.last_started_at,
+			last_finished_at = NULL,
+			last_status = EXCLUDED.last_status,
+			last_error = NULL`,
+		s.table,
+	), name, tick, StatusRunning); err != nil {
+		return fmt.Errorf("failed to record job start: %w", err)
+	}
+	return nil
+}
+
+// Finish records the result of the job run.
+func (s *postgresStore) Finish(ctx context.Context, name string, status Status, jobErr error) error {
+	var lastError sql.NullString
+	if jobErr != nil {
+		lastError = sql.NullString{String: jobErr.Error(), Valid: true}
+	}
+
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(
+		`UPDATE %s SET last_finished_at = NOW(), last_status = $2, last_error = $3 WHERE name = $1`,
+		s.table,
+	), name, status, lastError); err != nil {
+		return fmt.Errorf("failed to record job result: %w", err)
+	}
+	return nil
+}
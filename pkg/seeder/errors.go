@@ -0,0 +1,11 @@
+package seeder
+
+import "errors"
+
+// Predefined errors.
+var (
+	ErrInvalidSeedID = errors.New("seed id must not be empty")
+	ErrInvalidSeed   = errors.New("seed function must not be nil")
+	ErrDuplicateSeed = errors.New("duplicate seed id")
+	ErrNoFixtures    = errors.New("no fixture files match the pattern")
+)
@@ -0,0 +1,36 @@
+package seeder
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"sort"
+)
+
+// LoadFixtures executes the SQL files matching the patterns, e.g. "testdata/*.sql".
+// Files of every pattern are executed in the order of their names, patterns in the given order.
+// Fixtures are not tracked, load them into a fresh database or a transaction rolled back by the test.
+func LoadFixtures(ctx context.Context, db Execer, fsys fs.FS, patterns ...string) error {
+	for _, pattern := range patterns {
+		files, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return fmt.Errorf("invalid fixtures pattern %q: %w", pattern, err)
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("%w: %s", ErrNoFixtures, pattern)
+		}
+		sort.Strings(files)
+
+		for _, file := range files {
+			query, err := fs.ReadFile(fsys, file)
+			if err != nil {
+				return fmt.Errorf("failed to read fixture %s: %w", file, err)
+			}
+			if _, err := db.ExecContext(ctx, string(query)); err != nil {
+				return fmt.Errorf("failed to load fixture %s: %w", file, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package seeder
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+type (
+	// Func populates the database in the scope of the seed transaction.
+	Func func(ctx context.Context, tx *sql.Tx) error
+
+	// Seed is a named unit of data, applied once per database.
+	Seed struct {
+		ID  string
+		Run Func
+	}
+
+	// Seeder applies seeds and tracks applied ones in a table, like migrations.
+	Seeder struct {
+		db    *sql.DB
+		table string
+	}
+
+	// Option is a function that configures the Seeder.
+	Option func(*Seeder)
+
+	// Execer is the interface implemented by *sql.DB, *sql.Tx and *sql.Conn.
+	Execer interface {
+		ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	}
+)
+
+// New returns a new Seeder instance.
+func New(db *sql.DB, opts ...Option) *Seeder {
+	s := &Seeder{
+		db:    db,
+		table: "seeds",
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// WithTableName sets the name of the table used to track applied seeds.
+// Default is "seeds".
+func WithTableName(name string) Option {
+	return func(s *Seeder) {
+		if name != "" {
+			s.table = name
+		}
+	}
+}
+
+// SQL returns the seed executing the query.
+func SQL(id, query string) Seed {
+	return Seed{
+		ID: id,
+		Run: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, query)
+			return err
+		},
+	}
+}
+
+// FromFS returns SQL seeds from the *.sql files in the directory, ordered by file name.
+// The file path in fsys, e.g. "dev/001-users.sql", is used as the seed id,
+// so files with the same name in different directories are different seeds.
+// A missing directory contains no seeds.
+func FromFS(fsys fs.FS, dir string) ([]Seed, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read seeds directory %s: %w", dir, err)
+	}
+
+	var seeds []Seed
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+		id := path.Join(dir, e.Name())
+		query, err := fs.ReadFile(fsys, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read seed %s: %w", id, err)
+		}
+		seeds = append(seeds, SQL(id, string(query)))
+	}
+
+	return seeds, nil
+}
+
+// Sort orders seeds by id and checks that ids are unique.
+func Sort(seeds []Seed) ([]Seed, error) {
+	result := make([]Seed, len(seeds))
+	copy(result, seeds)
+	sort.SliceStable(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+
+	for i, s := range result {
+		if s.ID == "" {
+			return nil, ErrInvalidSeedID
+		}
+		if s.Run == nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSeed, s.ID)
+		}
+		if i > 0 && result[i-1].ID == s.ID {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateSeed, s.ID)
+		}
+	}
+
+	return result, nil
+}
+
+// Run applies the seeds which were not applied yet, in the given order.
+// Every seed runs in its own transaction together with its tracking record,
+// so a failed seed is rolled back and retried on the next run.
+// It returns the number of applied seeds.
+func (s *Seeder) Run(ctx context.Context, seeds ...Seed) (int, error) {
+	if _, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+pq.QuoteIdentifier(s.table)+` (
+		id TEXT PRIMARY KEY,
+		applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+	)`); err != nil {
+		return 0, fmt.Errorf("failed to create seeds table: %w", err)
+	}
+
+	applied := 0
+	for _, seed := range seeds {
+		ok, err := s.apply(ctx, seed)
+		if err != nil {
+			return applied, fmt.Errorf("failed to apply seed %s: %w", seed.ID, err)
+		}
+		if ok {
+			applied++
+		}
+	}
+
+	return applied, nil
+}
+
+// apply runs the seed in a transaction if it was not applied yet.
+func (s *Seeder) apply(ctx context.Context, seed Seed) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback() // nolint:errcheck
+
+	// The record is inserted first, so concurrent runs wait for each other on it.
+	res, err := tx.ExecContext(ctx,
+		"INSERT INTO "+pq.QuoteIdentifier(s.table)+" (id) VALUES ($1) ON CONFLICT (id) DO NOTHING",
+		seed.ID,
+	)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	if err := seed.Run(ctx, tx); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
@@ -0,0 +1,106 @@
+package seeder_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dmitrymomot/go-app/pkg/seeder"
+	"github.com/stretchr/testify/require"
+)
+
+// execer records executed queries.
+type execer struct {
+	queries []string
+}
+
+func (e *execer) ExecContext(_ context.Context, query string, _ ...interface{}) (sql.Result, error) {
+	e.queries = append(e.queries, query)
+	return nil, nil
+}
+
+func TestFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"seeds/dev/002-posts.sql":  {Data: []byte("INSERT INTO posts VALUES (1);")},
+		"seeds/dev/001-users.sql":  {Data: []byte("INSERT INTO users VALUES (1);")},
+		"seeds/dev/README.md":      {Data: []byte("# Seeds")},
+		"seeds/demo/001-users.sql": {Data: []byte("INSERT INTO users VALUES (2);")},
+	}
+
+	// Test case 1: SQL files are loaded ordered by name
+	t.Run("sql files are loaded ordered by name", func(t *testing.T) {
+		seeds, err := seeder.FromFS(fsys, "seeds/dev")
+		require.NoError(t, err)
+		require.Len(t, seeds, 2)
+		require.Equal(t, "seeds/dev/001-users.sql", seeds[0].ID)
+		require.Equal(t, "seeds/dev/002-posts.sql", seeds[1].ID)
+	})
+
+	// Test case 2: missing directory contains no seeds
+	t.Run("missing directory contains no seeds", func(t *testing.T) {
+		seeds, err := seeder.FromFS(fsys, "seeds/prod")
+		require.NoError(t, err)
+		require.Empty(t, seeds)
+	})
+
+	// Test case 3: files with the same name in different directories have different ids
+	t.Run("same names in different directories", func(t *testing.T) {
+		dev, err := seeder.FromFS(fsys, "seeds/dev")
+		require.NoError(t, err)
+		demo, err := seeder.FromFS(fsys, "seeds/demo")
+		require.NoError(t, err)
+		require.Len(t, demo, 1)
+		require.NotEqual(t, dev[0].ID, demo[0].ID)
+		require.Equal(t, "seeds/demo/001-users.sql", demo[0].ID)
+	})
+}
+
+func TestSort(t *testing.T) {
+	noop := func(context.Context, *sql.Tx) error { return nil }
+
+	// Test case 1: seeds are ordered by id
+	t.Run("seeds are ordered by id", func(t *testing.T) {
+		seeds, err := seeder.Sort([]seeder.Seed{{ID: "002-admin", Run: noop}, seeder.SQL("001-users.sql", "")})
+		require.NoError(t, err)
+		require.Equal(t, "001-users.sql", seeds[0].ID)
+		require.Equal(t, "002-admin", seeds[1].ID)
+	})
+
+	// Test case 2: invalid seeds are rejected
+	t.Run("invalid seeds are rejected", func(t *testing.T) {
+		_, err := seeder.Sort([]seeder.Seed{{ID: "", Run: noop}})
+		require.ErrorIs(t, err, seeder.ErrInvalidSeedID)
+
+		_, err = seeder.Sort([]seeder.Seed{{ID: "001-users"}})
+		require.ErrorIs(t, err, seeder.ErrInvalidSeed)
+
+		_, err = seeder.Sort([]seeder.Seed{{ID: "001-users", Run: noop}, {ID: "001-users", Run: noop}})
+		require.ErrorIs(t, err, seeder.ErrDuplicateSeed)
+	})
+}
+
+func TestLoadFixtures(t *testing.T) {
+	fsys := fstest.MapFS{
+		"testdata/users/2.sql": {Data: []byte("INSERT INTO users VALUES (2);")},
+		"testdata/users/1.sql": {Data: []byte("INSERT INTO users VALUES (1);")},
+		"testdata/posts.sql":   {Data: []byte("INSERT INTO posts VALUES (1);")},
+	}
+
+	// Test case 1: fixtures are executed in the order of patterns and file names
+	t.Run("fixtures are executed in order", func(t *testing.T) {
+		db := &execer{}
+		require.NoError(t, seeder.LoadFixtures(context.Background(), db, fsys, "testdata/users/*.sql", "testdata/posts.sql"))
+		require.Equal(t, []string{
+			"INSERT INTO users VALUES (1);",
+			"INSERT INTO users VALUES (2);",
+			"INSERT INTO posts VALUES (1);",
+		}, db.queries)
+	})
+
+	// Test case 2: pattern without files is an error
+	t.Run("pattern without files is an error", func(t *testing.T) {
+		err := seeder.LoadFixtures(context.Background(), &execer{}, fsys, "testdata/comments.sql")
+		require.ErrorIs(t, err, seeder.ErrNoFixtures)
+	})
+}
@@ -0,0 +1,84 @@
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// codec signs and optionally encrypts cookie values.
+type codec struct {
+	hashKey []byte
+	aead    cipher.AEAD
+}
+
+// newCodec creates a new cookie codec.
+// The encryption key is optional, without it cookie values are only signed.
+func newCodec(hashKey, encryptionKey []byte) (*codec, error) {
+	if len(hashKey) < 32 {
+		return nil, ErrInvalidSigningKey
+	}
+
+	c := &codec{hashKey: hashKey}
+	if len(encryptionKey) > 0 {
+		block, err := aes.NewCipher(encryptionKey)
+		if err != nil {
+			return nil, ErrInvalidEncryptKey
+		}
+		if c.aead, err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// encode returns the signed and optionally encrypted cookie value.
+// The cookie name is signed as well, so a value can't be moved to another cookie.
+func (c *codec) encode(name string, value []byte) (string, error) {
+	if c.aead != nil {
+		nonce := make([]byte, c.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+		value = c.aead.Seal(nonce, nonce, value, []byte(name))
+	}
+
+	return base64.RawURLEncoding.EncodeToString(append(value, c.mac(name, value)...)), nil
+}
+
+// decode verifies and decrypts the cookie value.
+func (c *codec) decode(name, encoded string) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(b) < sha256.Size {
+		return nil, ErrInvalidCookie
+	}
+
+	value, mac := b[:len(b)-sha256.Size], b[len(b)-sha256.Size:]
+	if !hmac.Equal(mac, c.mac(name, value)) {
+		return nil, ErrInvalidCookie
+	}
+
+	if c.aead != nil {
+		if len(value) < c.aead.NonceSize() {
+			return nil, ErrInvalidCookie
+		}
+		nonce, ciphertext := value[:c.aead.NonceSize()], value[c.aead.NonceSize():]
+		if value, err = c.aead.Open(nil, nonce, ciphertext, []byte(name)); err != nil {
+			return nil, ErrInvalidCookie
+		}
+	}
+
+	return value, nil
+}
+
+// mac returns the HMAC-SHA256 of the cookie name and value.
+func (c *codec) mac(name string, value []byte) []byte {
+	h := hmac.New(sha256.New, c.hashKey)
+	h.Write([]byte(name + "|")) // nolint:errcheck
+	h.Write(value)              // nolint:errcheck
+	return h.Sum(nil)
+}
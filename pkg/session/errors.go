@@ -0,0 +1,12 @@
+package session
+
+import "errors"
+
+// Predefined errors.
+var (
+	ErrNotFound          = errors.New("session not found")
+	ErrInvalidCookie     = errors.New("invalid session cookie")
+	ErrCookieTooLarge    = errors.New("session cookie exceeds the maximum size")
+	ErrInvalidSigningKey = errors.New("session signing key must be at least 32 bytes")
+	ErrInvalidEncryptKey = errors.New("session encryption key must be 16, 24 or 32 bytes")
+)
@@ -0,0 +1,223 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxCookieSize is the maximum size of a cookie supported by all major browsers.
+const maxCookieSize = 4096
+
+type (
+	// Manager loads and saves sessions of HTTP requests.
+	Manager struct {
+		store  Store
+		codec  *codec
+		logger logrus.FieldLogger
+
+		cookieName     string
+		cookiePath     string
+		cookieDomain   string
+		cookieSecure   bool
+		cookieSameSite http.SameSite
+
+		idleTimeout   time.Duration
+		lifetime      time.Duration
+		touchInterval time.Duration
+
+		encryptionKey []byte
+	}
+
+	// Option is a function that configures the Manager.
+	Option func(*Manager)
+)
+
+// NewManager creates a new session Manager.
+// The signing key must be at least 32 bytes long.
+func NewManager(store Store, signingKey []byte, opts ...Option) (*Manager, error) {
+	m := &Manager{
+		store:          store,
+		logger:         logrus.StandardLogger(),
+		cookieName:     "sid",
+		cookiePath:     "/",
+		cookieSecure:   true,
+		cookieSameSite: http.SameSiteLaxMode,
+		idleTimeout:    30 * time.Minute,
+		lifetime:       24 * time.Hour,
+		touchInterval:  time.Minute,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	c, err := newCodec(signingKey, m.encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	m.codec = c
+
+	return m, nil
+}
+
+// Middleware loads the session of the request and saves it
+// before the response headers are written.
+func (m *Manager) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := m.load(r)
+		ctx := context.WithValue(r.Context(), ctxKey{}, s)
+
+		sw := &responseWriter{ResponseWriter: w, commit: func() { m.save(w, r, s) }}
+		next.ServeHTTP(sw, r.WithContext(ctx))
+		sw.commitOnce()
+	})
+}
+
+// load returns the session of the request or a new session
+// if the cookie is missing, invalid or the session is expired.
+func (m *Manager) load(r *http.Request) *Session {
+	cookie, err := r.Cookie(m.cookieName)
+	if err != nil {
+		return newSession()
+	}
+
+	token, err := m.codec.decode(m.cookieName, cookie.Value)
+	if err != nil {
+		m.logger.WithError(err).Debug("Failed to decode session cookie")
+		return newSession()
+	}
+
+	b, err := m.store.Load(r.Context(), string(token))
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			m.logger.WithError(err).Error("Failed to load session")
+		}
+		return newSession()
+	}
+
+	s, err := decodeSession(string(token), b)
+	if err != nil {
+		m.logger.WithError(err).Error("Failed to decode session")
+		return newSession()
+	}
+
+	if now := time.Now(); m.expired(s, now) {
+		if err := m.store.Delete(r.Context(), s.token); err != nil {
+			m.logger.WithError(err).Error("Failed to delete expired session")
+		}
+		s = newSession()
+		s.expired = true // the cookie of the expired session must be removed
+	}
+
+	return s
+}
+
+// save stores the session and sets the session cookie.
+func (m *Manager) save(w http.ResponseWriter, r *http.Request, s *Session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx := r.Context()
+	now := time.Now()
+
+	if s.destroyed {
+		if s.token != "" {
+			if err := m.store.Delete(ctx, s.token); err != nil {
+				m.logger.WithError(err).Error("Failed to delete session")
+			}
+		}
+		m.setCookie(w, "", time.Unix(0, 0))
+		return
+	}
+
+	// Skip saving untouched sessions, but keep the idle timeout
+	// of the active sessions updated.
+	touch := s.token != "" && now.Sub(s.lastActiveAt) >= m.touchInterval
+	if !s.modified && !touch {
+		if s.expired {
+			m.setCookie(w, "", time.Unix(0, 0))
+		}
+		return
+	}
+
+	if s.renew && s.token != "" {
+		if err := m.store.Delete(ctx, s.token); err != nil {
+			m.logger.WithError(err).Error("Failed to delete session with the old ID")
+		}
+	}
+
+	s.lastActiveAt = now
+	b, err := s.encode()
+	if err != nil {
+		m.logger.WithError(err).Error("Failed to encode session")
+		return
+	}
+
+	expiresAt := m.expiresAt(s)
+	token, err := m.store.Save(ctx, s.id, b, expiresAt)
+	if err != nil {
+		m.logger.WithError(err).Error("Failed to save session")
+		return
+	}
+
+	value, err := m.codec.encode(m.cookieName, []byte(token))
+	if err != nil {
+		m.logger.WithError(err).Error("Failed to encode session cookie")
+		return
+	}
+	if len(value) > maxCookieSize {
+		m.logger.WithError(ErrCookieTooLarge).Error("Failed to save session")
+		return
+	}
+
+	s.token = token
+	s.modified = false
+	s.renew = false
+	m.setCookie(w, value, expiresAt)
+}
+
+// setCookie sets the session cookie with the given value.
+func (m *Manager) setCookie(w http.ResponseWriter, value string, expiresAt time.Time) {
+	cookie := &http.Cookie{
+		Name:     m.cookieName,
+		Value:    value,
+		Path:     m.cookiePath,
+		Domain:   m.cookieDomain,
+		Secure:   m.cookieSecure,
+		HttpOnly: true,
+		SameSite: m.cookieSameSite,
+		Expires:  expiresAt,
+	}
+	if value == "" {
+		cookie.MaxAge = -1
+	}
+
+	w.Header().Add("Set-Cookie", cookie.String())
+	w.Header().Add("Vary", "Cookie")
+}
+
+// expired checks the idle and absolute timeouts of the session.
+func (m *Manager) expired(s *Session, now time.Time) bool {
+	if m.idleTimeout > 0 && now.Sub(s.lastActiveAt) > m.idleTimeout {
+		return true
+	}
+	if m.lifetime > 0 && now.Sub(s.createdAt) > m.lifetime {
+		return true
+	}
+	return false
+}
+
+// expiresAt returns the time when the session expires by any of the timeouts.
+func (m *Manager) expiresAt(s *Session) time.Time {
+	expiresAt := s.createdAt.Add(m.lifetime)
+	if m.idleTimeout > 0 {
+		if idle := s.lastActiveAt.Add(m.idleTimeout); m.lifetime <= 0 || idle.Before(expiresAt) {
+			expiresAt = idle
+		}
+	}
+	return expiresAt
+}
@@ -0,0 +1,84 @@
+package session
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WithEncryptionKey enables AES-GCM encryption of the session cookie.
+// The key must be 16, 24 or 32 bytes long.
+func WithEncryptionKey(key []byte) Option {
+	return func(m *Manager) {
+		m.encryptionKey = key
+	}
+}
+
+// WithCookieName sets the name of the session cookie. Default is "sid".
+func WithCookieName(name string) Option {
+	return func(m *Manager) {
+		if name != "" {
+			m.cookieName = name
+		}
+	}
+}
+
+// WithCookiePath sets the path of the session cookie. Default is "/".
+func WithCookiePath(path string) Option {
+	return func(m *Manager) {
+		if path != "" {
+			m.cookiePath = path
+		}
+	}
+}
+
+// WithCookieDomain sets the domain of the session cookie.
+func WithCookieDomain(domain string) Option {
+	return func(m *Manager) {
+		m.cookieDomain = domain
+	}
+}
+
+// WithCookieSecure sets the Secure flag of the session cookie. Default is true.
+func WithCookieSecure(secure bool) Option {
+	return func(m *Manager) {
+		m.cookieSecure = secure
+	}
+}
+
+// WithCookieSameSite sets the SameSite attribute of the session cookie.
+// Default is http.SameSiteLaxMode.
+func WithCookieSameSite(sameSite http.SameSite) Option {
+	return func(m *Manager) {
+		m.cookieSameSite = sameSite
+	}
+}
+
+// WithIdleTimeout sets the maximum time of inactivity before the session expires.
+// Zero disables the idle timeout. Default is 30 minutes.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(m *Manager) {
+		m.idleTimeout = d
+	}
+}
+
+// WithLifetime sets the absolute timeout of the session, regardless of activity.
+// Default is 24 hours.
+func WithLifetime(d time.Duration) Option {
+	return func(m *Manager) {
+		if d > 0 {
+			m.lifetime = d
+		}
+	}
+}
+
+// WithLogger sets the logger for the manager.
+// Default is the logrus standard logger.
+func WithLogger(l logrus.FieldLogger) Option {
+	return func(m *Manager) {
+		if l != nil {
+			m.logger = l
+		}
+	}
+}
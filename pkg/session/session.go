@@ -0,0 +1,208 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/gob"
+	"sync"
+	"time"
+)
+
+// flashesKey is the key of the flash messages in the session values.
+const flashesKey = "_flashes"
+
+type (
+	// Session is a per-request view of the user session.
+	// Values must be registered with gob.Register if they are not basic types.
+	Session struct {
+		mu           sync.RWMutex
+		id           string
+		token        string // token of the loaded session, empty for a new session
+		values       map[string]interface{}
+		createdAt    time.Time
+		lastActiveAt time.Time
+		modified     bool
+		renew        bool
+		destroyed    bool
+		expired      bool // the request had a cookie of an expired session
+	}
+
+	// Flash is a one-time message shown to the user on the next page.
+	Flash struct {
+		Kind    string
+		Message string
+	}
+
+	// data is the serialized form of the session.
+	data struct {
+		ID           string
+		Values       map[string]interface{}
+		CreatedAt    time.Time
+		LastActiveAt time.Time
+	}
+
+	// ctxKey is the context key of the session.
+	ctxKey struct{}
+)
+
+func init() {
+	gob.Register(Flash{})
+	gob.Register([]Flash{})
+}
+
+// newSession creates a new empty session.
+func newSession() *Session {
+	now := time.Now()
+	return &Session{
+		id:           newID(),
+		values:       make(map[string]interface{}),
+		createdAt:    now,
+		lastActiveAt: now,
+	}
+}
+
+// FromContext returns the session of the request.
+// It returns nil if the session middleware is not installed.
+func FromContext(ctx context.Context) *Session {
+	s, _ := ctx.Value(ctxKey{}).(*Session)
+	return s
+}
+
+// ID returns the session ID.
+func (s *Session) ID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.id
+}
+
+// IsNew returns true if the session was not loaded from the store,
+// i.e. the request had no valid session cookie.
+func (s *Session) IsNew() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.token == ""
+}
+
+// CreatedAt returns the time when the session was created.
+func (s *Session) CreatedAt() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.createdAt
+}
+
+// Get returns the value for the given key or nil if it does not exist.
+func (s *Session) Get(key string) interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.values[key]
+}
+
+// GetString returns the string value for the given key
+// or an empty string if it does not exist or is not a string.
+func (s *Session) GetString(key string) string {
+	v, _ := s.Get(key).(string)
+	return v
+}
+
+// Set sets the value for the given key.
+func (s *Session) Set(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+	s.modified = true
+}
+
+// Delete removes the value for the given key.
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.values[key]; ok {
+		delete(s.values, key)
+		s.modified = true
+	}
+}
+
+// AddFlash adds a flash message to the session.
+func (s *Session) AddFlash(kind, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	flashes, _ := s.values[flashesKey].([]Flash)
+	s.values[flashesKey] = append(flashes, Flash{Kind: kind, Message: message})
+	s.modified = true
+}
+
+// Flashes returns all flash messages and removes them from the session.
+func (s *Session) Flashes() []Flash {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	flashes, ok := s.values[flashesKey].([]Flash)
+	if !ok {
+		return nil
+	}
+	delete(s.values, flashesKey)
+	s.modified = true
+	return flashes
+}
+
+// RenewID generates a new session ID keeping the session values.
+// It must be called on every privilege change, e.g. login or logout,
+// to prevent session fixation attacks.
+func (s *Session) RenewID() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.id = newID()
+	s.renew = true
+	s.modified = true
+}
+
+// Destroy removes all session values and deletes the session from the store.
+func (s *Session) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = make(map[string]interface{})
+	s.destroyed = true
+	s.modified = true
+}
+
+// encode serializes the session with gob.
+func (s *Session) encode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(data{
+		ID:           s.id,
+		Values:       s.values,
+		CreatedAt:    s.createdAt,
+		LastActiveAt: s.lastActiveAt,
+	}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeSession deserializes the session loaded by the given token.
+func decodeSession(token string, b []byte) (*Session, error) {
+	var d data
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&d); err != nil {
+		return nil, err
+	}
+	if d.Values == nil {
+		d.Values = make(map[string]interface{})
+	}
+	return &Session{
+		id:           d.ID,
+		token:        token,
+		values:       d.Values,
+		createdAt:    d.CreatedAt,
+		lastActiveAt: d.LastActiveAt,
+	}, nil
+}
+
+// newID returns a new random session ID.
+func newID() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
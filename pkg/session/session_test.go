@@ -0,0 +1,199 @@
+package session_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dmitrymomot/go-app/pkg/session"
+	"github.com/stretchr/testify/require"
+)
+
+var signingKey = []byte("0123456789abcdef0123456789abcdef")
+
+// memoryStore is an in-memory implementation of the session.Store interface.
+type memoryStore struct {
+	mu    sync.Mutex
+	items map[string][]byte
+}
+
+func (s *memoryStore) Load(_ context.Context, token string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.items[token]
+	if !ok {
+		return nil, session.ErrNotFound
+	}
+	return b, nil
+}
+
+func (s *memoryStore) Save(_ context.Context, id string, data []byte, _ time.Time) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[id] = data
+	return id, nil
+}
+
+func (s *memoryStore) Delete(_ context.Context, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, token)
+	return nil
+}
+
+// client keeps the session cookie between requests.
+type client struct {
+	handler http.Handler
+	cookie  *http.Cookie
+}
+
+func (c *client) do(t *testing.T, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if c.cookie != nil {
+		req.AddCookie(c.cookie)
+	}
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.MaxAge < 0 {
+			c.cookie = nil
+			continue
+		}
+		c.cookie = cookie
+	}
+	return rec
+}
+
+func newHandler(t *testing.T, store session.Store, opts ...session.Option) http.Handler {
+	m, err := session.NewManager(store, signingKey, opts...)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/set", func(w http.ResponseWriter, r *http.Request) {
+		session.FromContext(r.Context()).Set("user", "john")
+	})
+	mux.HandleFunc("/get", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(session.FromContext(r.Context()).GetString("user"))) // nolint:errcheck
+	})
+	mux.HandleFunc("/flash", func(w http.ResponseWriter, r *http.Request) {
+		session.FromContext(r.Context()).AddFlash("info", "saved")
+	})
+	mux.HandleFunc("/flashes", func(w http.ResponseWriter, r *http.Request) {
+		for _, f := range session.FromContext(r.Context()).Flashes() {
+			w.Write([]byte(f.Kind + ":" + f.Message)) // nolint:errcheck
+		}
+	})
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		s := session.FromContext(r.Context())
+		s.RenewID()
+		s.Set("role", "admin")
+	})
+	mux.HandleFunc("/id", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(session.FromContext(r.Context()).ID())) // nolint:errcheck
+	})
+	mux.HandleFunc("/logout", func(w http.ResponseWriter, r *http.Request) {
+		session.FromContext(r.Context()).Destroy()
+	})
+
+	return m.Middleware(mux)
+}
+
+func TestManager(t *testing.T) {
+	// Test case 1: encrypted cookie session keeps values between requests
+	t.Run("encrypted cookie session keeps values between requests", func(t *testing.T) {
+		c := &client{handler: newHandler(t, session.NewCookieStore(),
+			session.WithEncryptionKey([]byte("0123456789abcdef")),
+		)}
+
+		rec := c.do(t, "/get")
+		require.Empty(t, rec.Body.String())
+		require.Nil(t, c.cookie, "untouched session must not be saved")
+
+		c.do(t, "/set")
+		require.NotNil(t, c.cookie)
+		require.True(t, c.cookie.HttpOnly)
+		require.NotContains(t, c.cookie.Value, "john")
+
+		rec = c.do(t, "/get")
+		require.Equal(t, "john", rec.Body.String())
+	})
+
+	// Test case 2: tampered cookie is ignored
+	t.Run("tampered cookie is ignored", func(t *testing.T) {
+		c := &client{handler: newHandler(t, session.NewCookieStore())}
+		c.do(t, "/set")
+		require.NotNil(t, c.cookie)
+
+		c.cookie.Value = "x" + c.cookie.Value[1:]
+		rec := c.do(t, "/get")
+		require.Empty(t, rec.Body.String())
+	})
+
+	// Test case 3: flash messages are shown once
+	t.Run("flash messages are shown once", func(t *testing.T) {
+		c := &client{handler: newHandler(t, session.NewCookieStore())}
+		c.do(t, "/flash")
+
+		rec := c.do(t, "/flashes")
+		require.Equal(t, "info:saved", rec.Body.String())
+
+		rec = c.do(t, "/flashes")
+		require.Empty(t, rec.Body.String())
+	})
+
+	// Test case 4: session ID is rotated on privilege change
+	t.Run("session ID is rotated on privilege change", func(t *testing.T) {
+		store := &memoryStore{items: make(map[string][]byte)}
+		c := &client{handler: newHandler(t, store)}
+
+		c.do(t, "/set")
+		oldID := c.do(t, "/id").Body.String()
+		require.Contains(t, store.items, oldID)
+
+		c.do(t, "/login")
+		newID := c.do(t, "/id").Body.String()
+		require.NotEqual(t, oldID, newID)
+		require.NotContains(t, store.items, oldID)
+		require.Contains(t, store.items, newID)
+		require.Equal(t, "john", c.do(t, "/get").Body.String())
+	})
+
+	// Test case 5: session expires after idle timeout
+	t.Run("session expires after idle timeout", func(t *testing.T) {
+		store := &memoryStore{items: make(map[string][]byte)}
+		c := &client{handler: newHandler(t, store, session.WithIdleTimeout(50*time.Millisecond))}
+
+		c.do(t, "/set")
+		require.Equal(t, "john", c.do(t, "/get").Body.String())
+
+		time.Sleep(100 * time.Millisecond)
+		require.Empty(t, c.do(t, "/get").Body.String())
+		require.Nil(t, c.cookie)
+		require.Empty(t, store.items)
+	})
+
+	// Test case 6: destroyed session is removed
+	t.Run("destroyed session is removed", func(t *testing.T) {
+		store := &memoryStore{items: make(map[string][]byte)}
+		c := &client{handler: newHandler(t, store)}
+
+		c.do(t, "/set")
+		require.Len(t, store.items, 1)
+
+		c.do(t, "/logout")
+		require.Nil(t, c.cookie)
+		require.Empty(t, store.items)
+	})
+}
+
+func TestNewManager(t *testing.T) {
+	_, err := session.NewManager(session.NewCookieStore(), []byte("short"))
+	require.ErrorIs(t, err, session.ErrInvalidSigningKey)
+
+	_, err = session.NewManager(session.NewCookieStore(), signingKey, session.WithEncryptionKey([]byte("short")))
+	require.ErrorIs(t, err, session.ErrInvalidEncryptKey)
+}
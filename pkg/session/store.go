@@ -0,0 +1,59 @@
+package session
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// Store is the interface of the session storage.
+	// The token is the value kept in the session cookie (signed and optionally encrypted):
+	// server-side stores use the session ID as the token, the cookie store keeps
+	// the whole serialized session in the token.
+	Store interface {
+		// Load returns the serialized session by the token.
+		// It returns ErrNotFound if the session does not exist or is expired.
+		Load(ctx context.Context, token string) ([]byte, error)
+
+		// Save stores the serialized session and returns the token
+		// which must be kept in the session cookie.
+		Save(ctx context.Context, id string, data []byte, expiresAt time.Time) (string, error)
+
+		// Delete removes the session by the token.
+		Delete(ctx context.Context, token string) error
+	}
+
+	// Cleaner is implemented by the stores which need periodic removal
+	// of expired sessions, e.g. by a scheduled job.
+	Cleaner interface {
+		DeleteExpired(ctx context.Context) error
+	}
+
+	// cookieStore keeps the whole session in the cookie.
+	cookieStore struct{}
+)
+
+// NewCookieStore returns a new Store instance which keeps the session in the cookie.
+// It's suitable for small sessions only, browsers limit cookies to 4KB.
+// Use an encryption key with this store if the session contains sensitive data.
+func NewCookieStore() Store {
+	return cookieStore{}
+}
+
+// Load returns the serialized session kept in the token.
+func (cookieStore) Load(_ context.Context, token string) ([]byte, error) {
+	if token == "" {
+		return nil, ErrNotFound
+	}
+	return []byte(token), nil
+}
+
+// Save returns the serialized session as the token.
+func (cookieStore) Save(_ context.Context, _ string, data []byte, _ time.Time) (string, error) {
+	return string(data), nil
+}
+
+// Delete does nothing, the cookie is removed by the manager.
+func (cookieStore) Delete(context.Context, string) error {
+	return nil
+}
@@ -0,0 +1,71 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// postgresStore is a Postgres implementation of the Store interface.
+// The table is created by the application migrations,
+// see internal/repository/sql/migrations.
+type postgresStore struct {
+	db    *sql.DB
+	table string
+}
+
+// NewPostgresStore returns a new Store instance backed by the given table.
+// If the table name is empty, "sessions" is used.
+func NewPostgresStore(db *sql.DB, table string) Store {
+	if table == "" {
+		table = "sessions"
+	}
+	return &postgresStore{
+		db:    db,
+		table: pq.QuoteIdentifier(table),
+	}
+}
+
+// Load returns the serialized session by the session ID.
+func (s *postgresStore) Load(ctx context.Context, token string) ([]byte, error) {
+	var data []byte
+	if err := s.db.QueryRowContext(ctx, fmt.Sprintf(
+		`SELECT data FROM %s WHERE id = $1 AND expires_at > NOW()`, s.table,
+	), token).Scan(&data); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+	return data, nil
+}
+
+// Save stores the serialized session and returns the session ID as the token.
+func (s *postgresStore) Save(ctx context.Context, id string, data []byte, expiresAt time.Time) (string, error) {
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(
+		`INSERT INTO %s (id, data, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET data = EXCLUDED.data, expires_at = EXCLUDED.expires_at`, s.table,
+	), id, data, expiresAt); err != nil {
+		return "", fmt.Errorf("failed to save session: %w", err)
+	}
+	return id, nil
+}
+
+// Delete removes the session by the session ID.
+func (s *postgresStore) Delete(ctx context.Context, token string) error {
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, s.table), token); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes all expired sessions.
+func (s *postgresStore) DeleteExpired(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE expires_at <= NOW()`, s.table)); err != nil {
+		return fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisStore is a Redis implementation of the Store interface.
+// Sessions are removed by Redis when they expire.
+type redisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisStore returns a new Store instance backed by Redis.
+// If the key prefix is empty, "session:" is used.
+func NewRedisStore(client redis.UniversalClient, prefix string) Store {
+	if prefix == "" {
+		prefix = "session:"
+	}
+	return &redisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Load returns the serialized session by the session ID.
+func (s *redisStore) Load(ctx context.Context, token string) ([]byte, error) {
+	data, err := s.client.Get(ctx, s.prefix+token).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+	return data, nil
+}
+
+// Save stores the serialized session and returns the session ID as the token.
+func (s *redisStore) Save(ctx context.Context, id string, data []byte, expiresAt time.Time) (string, error) {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return "", s.Delete(ctx, id)
+	}
+	if err := s.client.Set(ctx, s.prefix+id, data, ttl).Err(); err != nil {
+		return "", fmt.Errorf("failed to save session: %w", err)
+	}
+	return id, nil
+}
+
+// Delete removes the session by the session ID.
+func (s *redisStore) Delete(ctx context.Context, token string) error {
+	if err := s.client.Del(ctx, s.prefix+token).Err(); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package session
+
+import (
+	"net/http"
+	"sync"
+)
+
+// responseWriter saves the session right before the response headers are written,
+// so the session cookie can still be set.
+type responseWriter struct {
+	http.ResponseWriter
+	commit func()
+	once   sync.Once
+}
+
+// commitOnce saves the session if it has not been saved yet.
+func (w *responseWriter) commitOnce() {
+	w.once.Do(w.commit)
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *responseWriter) WriteHeader(status int) {
+	w.commitOnce()
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter.
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.commitOnce()
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher.
+func (w *responseWriter) Flush() {
+	w.commitOnce()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the original http.ResponseWriter, used by http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,116 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	// DBTX is the interface of *sql.DB and *sql.Tx used by the sqlc generated code.
+	DBTX interface {
+		ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+		PrepareContext(context.Context, string) (*sql.Stmt, error)
+		QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+		QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+	}
+
+	// tracedDB creates a client span for every query.
+	tracedDB struct {
+		db     DBTX
+		tracer trace.Tracer
+	}
+)
+
+// WrapDB wraps the database or transaction to create a child span for every query.
+// The span is named by the sqlc query name ("-- name: GetUser :one"), or by the SQL operation otherwise.
+// Query arguments are not recorded.
+func WrapDB(db DBTX) DBTX {
+	return &tracedDB{db: db, tracer: otel.Tracer(instrumentationName)}
+}
+
+// ExecContext implements DBTX.
+func (d *tracedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := d.start(ctx, query)
+	res, err := d.db.ExecContext(ctx, query, args...)
+	end(span, err)
+	return res, err
+}
+
+// PrepareContext implements DBTX.
+func (d *tracedDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, span := d.start(ctx, query)
+	stmt, err := d.db.PrepareContext(ctx, query)
+	end(span, err)
+	return stmt, err
+}
+
+// QueryContext implements DBTX.
+// The span covers the query execution, not reading the rows.
+func (d *tracedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := d.start(ctx, query)
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	end(span, err)
+	return rows, err
+}
+
+// QueryRowContext implements DBTX.
+func (d *tracedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := d.start(ctx, query)
+	row := d.db.QueryRowContext(ctx, query, args...)
+	end(span, row.Err())
+	return row
+}
+
+// start starts the client span of the query.
+func (d *tracedDB) start(ctx context.Context, query string) (context.Context, trace.Span) {
+	name, operation := queryName(query)
+	return d.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperation(operation),
+			semconv.DBStatement(query),
+			attribute.String("db.query_name", name),
+		),
+	)
+}
+
+// end records the error, if any, and ends the span.
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// queryName returns the sqlc query name and the SQL operation of the query.
+func queryName(query string) (name, operation string) {
+	query = strings.TrimSpace(query)
+	if strings.HasPrefix(query, "-- name: ") {
+		line, rest, _ := strings.Cut(query, "\n")
+		if fields := strings.Fields(strings.TrimPrefix(line, "-- name: ")); len(fields) > 0 {
+			name = fields[0]
+		}
+		query = strings.TrimSpace(rest)
+	}
+
+	if fields := strings.Fields(query); len(fields) > 0 {
+		operation = strings.ToUpper(fields[0])
+	}
+	if name == "" {
+		name = operation
+	}
+	if name == "" {
+		name = "query"
+	}
+
+	return name, operation
+}
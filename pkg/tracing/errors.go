@@ -0,0 +1,10 @@
+package tracing
+
+import "errors"
+
+// Predefined errors.
+var (
+	ErrUnknownExporter    = errors.New("unknown tracing exporter")
+	ErrMissingFilePath    = errors.New("file exporter requires a file path")
+	ErrInvalidSampleRatio = errors.New("sample ratio must be between 0 and 1")
+)
@@ -0,0 +1,43 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LogrusHook adds trace_id and span_id fields to log entries with the traced context,
+// i.e. logged with logger.WithContext(ctx).
+type LogrusHook struct{}
+
+// Levels implements logrus.Hook.
+func (LogrusHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook.
+func (LogrusHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	for k, v := range LogFields(entry.Context) {
+		if _, ok := entry.Data[k]; !ok {
+			entry.Data[k] = v
+		}
+	}
+	return nil
+}
+
+// LogFields returns trace_id and span_id fields of the span from the context,
+// or nil if the context is not traced.
+func LogFields(ctx context.Context) logrus.Fields {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return logrus.Fields{
+		"trace_id": sc.TraceID().String(),
+		"span_id":  sc.SpanID().String(),
+	}
+}
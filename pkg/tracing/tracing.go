@@ -0,0 +1,177 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// Supported exporters.
+const (
+	// ExporterNone disables exporting, spans are still created to propagate the trace context.
+	ExporterNone = "none"
+	// ExporterOTLP sends spans to the OpenTelemetry collector.
+	// It's configured with standard OTEL_EXPORTER_OTLP_* environment variables,
+	// OTEL_EXPORTER_OTLP_PROTOCOL is either "http/protobuf" (default) or "grpc".
+	ExporterOTLP = "otlp"
+	// ExporterStdout writes spans to stdout as JSON, for local development.
+	ExporterStdout = "stdout"
+	// ExporterFile writes spans to the file as JSON lines, for local development and tests.
+	ExporterFile = "file"
+)
+
+type (
+	// Option is a function that configures the tracer provider.
+	Option func(*options)
+
+	options struct {
+		serviceName    string
+		serviceVersion string
+		exporter       string
+		filePath       string
+		sampleRatio    float64
+	}
+)
+
+// Init sets up the global tracer provider with the exporter and the W3C trace context propagator.
+// The returned function flushes pending spans and must be called on shutdown.
+func Init(ctx context.Context, opts ...Option) (shutdown func(context.Context) error, err error) {
+	o := &options{
+		serviceName: "app",
+		exporter:    ExporterNone,
+		sampleRatio: 1,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.sampleRatio < 0 || o.sampleRatio > 1 {
+		return nil, ErrInvalidSampleRatio
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(o.serviceName),
+		semconv.ServiceVersion(o.serviceVersion),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracing resource: %w", err)
+	}
+
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(o.sampleRatio))),
+	}
+
+	var closer io.Closer
+	switch o.exporter {
+	case ExporterNone, "":
+	case ExporterOTLP:
+		exp, err := newOTLPExporter(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exp))
+	case ExporterStdout:
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+		providerOpts = append(providerOpts, sdktrace.WithSyncer(exp))
+	case ExporterFile:
+		if o.filePath == "" {
+			return nil, ErrMissingFilePath
+		}
+		f, err := os.OpenFile(o.filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open traces file: %w", err)
+		}
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to create file exporter: %w", err)
+		}
+		closer = f
+		providerOpts = append(providerOpts, sdktrace.WithSyncer(exp))
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownExporter, o.exporter)
+	}
+
+	tp := sdktrace.NewTracerProvider(providerOpts...)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if closer != nil {
+			if cerr := closer.Close(); err == nil {
+				err = cerr
+			}
+		}
+		return err
+	}, nil
+}
+
+// newOTLPExporter creates the OTLP exporter with the protocol from the environment.
+func newOTLPExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	protocol := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL")
+	if protocol == "" {
+		protocol = os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+
+	switch strings.ToLower(protocol) {
+	case "grpc":
+		return otlptracegrpc.New(ctx)
+	case "", "http/protobuf":
+		return otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol: %s", protocol)
+	}
+}
+
+// WithService sets the service name and version of the resource.
+// The version is usually the commit hash.
+func WithService(name, version string) Option {
+	return func(o *options) {
+		if name != "" {
+			o.serviceName = name
+		}
+		o.serviceVersion = version
+	}
+}
+
+// WithExporter sets the exporter: none (default), otlp, stdout or file.
+func WithExporter(exporter string) Option {
+	return func(o *options) {
+		o.exporter = strings.ToLower(exporter)
+	}
+}
+
+// WithFile sets the path of the file exporter.
+func WithFile(path string) Option {
+	return func(o *options) {
+		o.filePath = path
+	}
+}
+
+// WithSampleRatio sets the ratio of sampled root traces, from 0 to 1.
+// Traces started by callers follow their sampling decision.
+// Default is 1, i.e. all traces are sampled.
+func WithSampleRatio(ratio float64) Option {
+	return func(o *options) {
+		o.sampleRatio = ratio
+	}
+}
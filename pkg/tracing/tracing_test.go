@@ -0,0 +1,138 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dmitrymomot/go-app/pkg/tracing"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	return rec
+}
+
+type fakeDB struct {
+	tracing.DBTX
+	err error
+}
+
+func (f fakeDB) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	return nil, f.err
+}
+
+func TestMiddleware(t *testing.T) {
+	rec := setupRecorder(t)
+
+	r := chi.NewRouter()
+	r.Use(tracing.Middleware)
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := rec.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, "GET /users/{id}", spans[0].Name())
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+}
+
+func TestWrapDB(t *testing.T) {
+	rec := setupRecorder(t)
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	db := tracing.WrapDB(fakeDB{})
+	_, err := db.ExecContext(ctx, "-- name: DeleteUser :exec\nDELETE FROM users WHERE id = $1", 1)
+	require.NoError(t, err)
+
+	failing := tracing.WrapDB(fakeDB{err: errors.New("connection refused")})
+	_, err = failing.ExecContext(ctx, "update users set name = $1", "john")
+	require.Error(t, err)
+	parent.End()
+
+	spans := rec.Ended()
+	require.Len(t, spans, 3)
+	require.Equal(t, "DeleteUser", spans[0].Name())
+	require.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	require.Equal(t, "UPDATE", spans[1].Name())
+	require.Equal(t, codes.Error, spans[1].Status().Code)
+}
+
+func TestLogrusHook(t *testing.T) {
+	setupRecorder(t)
+
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.AddHook(tracing.LogrusHook{})
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "span")
+	defer span.End()
+
+	logger.WithContext(ctx).Info("traced")
+	require.Contains(t, buf.String(), `"trace_id":"`+span.SpanContext().TraceID().String()+`"`)
+	require.Contains(t, buf.String(), `"span_id":"`+span.SpanContext().SpanID().String()+`"`)
+
+	buf.Reset()
+	logger.WithContext(context.Background()).Info("not traced")
+	require.NotContains(t, buf.String(), "trace_id")
+}
+
+func TestInit(t *testing.T) {
+	// Test case 1: file exporter writes spans
+	t.Run("file exporter writes spans", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "traces.json")
+		shutdown, err := tracing.Init(context.Background(),
+			tracing.WithService("test", "abc123"),
+			tracing.WithExporter(tracing.ExporterFile),
+			tracing.WithFile(path),
+		)
+		require.NoError(t, err)
+
+		_, span := otel.Tracer("test").Start(context.Background(), "file-span")
+		span.End()
+		require.NoError(t, shutdown(context.Background()))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Contains(t, string(data), "file-span")
+		require.Contains(t, string(data), "abc123")
+	})
+
+	// Test case 2: invalid options fail
+	t.Run("invalid options fail", func(t *testing.T) {
+		_, err := tracing.Init(context.Background(), tracing.WithExporter("jaeger"))
+		require.ErrorIs(t, err, tracing.ErrUnknownExporter)
+
+		_, err = tracing.Init(context.Background(), tracing.WithExporter(tracing.ExporterFile))
+		require.ErrorIs(t, err, tracing.ErrMissingFilePath)
+
+		_, err = tracing.Init(context.Background(), tracing.WithSampleRatio(2))
+		require.ErrorIs(t, err, tracing.ErrInvalidSampleRatio)
+	})
+}
@@ -1,3 +1,4 @@
 #!/bin/bash
-bin/migrate && \
-bin/app
\ No newline at end of file
+# Usage: scripts/run.sh [serve|worker|scheduler|all]
+bin/app migrate && \
+exec bin/app "${1:-all}"